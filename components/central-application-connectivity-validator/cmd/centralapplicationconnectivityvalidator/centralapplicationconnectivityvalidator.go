@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	"github.com/kyma-project/kyma/common/logging/logger"
 	"github.com/kyma-project/kyma/common/logging/tracing"
@@ -31,7 +33,8 @@ import (
 )
 
 const (
-	shutdownTimeout = 2 * time.Second
+	shutdownTimeout     = 2 * time.Second
+	cacheWarmRetryDelay = time.Second
 )
 
 var (
@@ -97,27 +100,13 @@ func main() {
 			Warnf("Deleted the application from the cache with values %v.", i)
 	})
 
-	proxyHandler := validationproxy.NewProxyHandler(
-		options.eventingPublisherHost,
-		options.eventingDestinationPath,
-		idCache,
-		log)
-
-	tracingMiddleware := tracing.NewTracingMiddleware(proxyHandler.ProxyAppConnectorRequests)
-
-	proxyServer := http.Server{
-		Handler: validationproxy.NewHandler(tracingMiddleware),
-		Addr:    fmt.Sprintf(":%d", options.proxyPort),
-	}
-
-	externalServer := http.Server{
-		Handler: externalapi.NewHandler(),
-		Addr:    fmt.Sprintf(":%d", options.externalAPIPort),
+	if err := validationproxy.RegisterMetrics(metrics.Registry, validationproxy.NewCachedApplicationsGauge(idCache)); err != nil {
+		log.WithContext().Warnf("Unable to register some metrics, they are not exposed: %s", err.Error())
 	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: "0",
+		MetricsBindAddress: options.metricsBindAddress,
 		SyncPeriod:         &options.syncPeriod,
 		ClientDisableCacheFor: []client.Object{
 			&v1alpha1.Application{},
@@ -127,6 +116,10 @@ func main() {
 		log.WithContext().Error("Unable to start manager: %s", err.Error())
 		os.Exit(1)
 	}
+	var cacheSyncOptions []controller.CacheSyncOption
+	if options.clientIDsConfigMapName != "" {
+		cacheSyncOptions = append(cacheSyncOptions, controller.WithClientIDFallback())
+	}
 	if err = controller.NewController(
 		log,
 		mgr.GetClient(),
@@ -134,15 +127,175 @@ func main() {
 		options.appNamePlaceholder,
 		options.eventingPathPrefixV1,
 		options.eventingPathPrefixV2,
-		options.eventingPathPrefixEvents).SetupWithManager(mgr); err != nil {
+		options.eventingPathPrefixEvents,
+		controller.WithMaxConcurrentReconciles(options.maxConcurrentReconciles),
+		controller.WithCacheSyncOptions(cacheSyncOptions...)).SetupWithManager(mgr); err != nil {
 		log.WithContext().Error("Unable to create reconciler: %s", err.Error())
 		os.Exit(1)
 	}
 
+	eventsAPIRoutes, err := parseEventsAPIRoutes(options.eventsAPIRoutes, options.appNamePlaceholder)
+	if err != nil {
+		log.WithContext().Error("Unable to parse events API routes: %s", err.Error())
+		os.Exit(1)
+	}
+
+	proxyHandlerOptions := []validationproxy.Option{
+		validationproxy.WithEventsAPIRoutes(eventsAPIRoutes...),
+		validationproxy.WithSubjectValidationMode(validationproxy.SubjectValidationMode(options.subjectValidationMode)),
+		validationproxy.WithSANURIValidation(validationproxy.SANValidationMode(options.sanValidationMode), options.sanURIPrefix),
+		validationproxy.WithLogSampling(options.logSamplingRate),
+		validationproxy.WithClientIDFetchFailurePolicy(validationproxy.ClientIDFetchFailurePolicy(options.clientIDFetchFailurePolicy)),
+		validationproxy.WithMaxCertificateHeaderLength(options.maxCertHeaderLength),
+		validationproxy.WithResponseHeaderTimeout(validationproxy.DestinationLegacyEvents, options.legacyEventsResponseTimeout),
+		validationproxy.WithResponseHeaderTimeout(validationproxy.DestinationCloudEvents, options.cloudEventsResponseTimeout),
+		validationproxy.WithFlushInterval(validationproxy.DestinationLegacyEvents, options.legacyEventsFlushInterval),
+		validationproxy.WithFlushInterval(validationproxy.DestinationCloudEvents, options.cloudEventsFlushInterval),
+	}
+	pathRedactionPatterns, err := parsePathRedactionPatterns(options.pathRedactionPatterns)
+	if err != nil {
+		log.WithContext().Error("Unable to parse path redaction patterns: %s", err.Error())
+		os.Exit(1)
+	}
+	for _, destination := range splitList(options.disabledDestinations) {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithDisabledDestinations(validationproxy.Destination(destination)))
+	}
+	if options.fallbackDestination != "" {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithFallbackDestination(validationproxy.Destination(options.fallbackDestination)))
+	}
+	for _, destination := range splitList(options.originalHostDestinations) {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithOriginalHost(validationproxy.Destination(destination)))
+	}
+	if methods := splitList(options.legacyEventsAllowedMethods); len(methods) > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithAllowedMethods(validationproxy.DestinationLegacyEvents, methods...))
+	}
+	if methods := splitList(options.cloudEventsAllowedMethods); len(methods) > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithAllowedMethods(validationproxy.DestinationCloudEvents, methods...))
+	}
+	if options.subjectDelimiter != "" {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithSubjectDelimiter(options.subjectDelimiter))
+	}
+	proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithSubjectAttributes(validationproxy.SubjectAttributes{
+		Identity:           options.subjectIdentityAttribute,
+		Organization:       options.subjectTenantAttribute,
+		OrganizationalUnit: options.subjectGroupAttribute,
+	}))
+	if attributes := splitList(options.requiredSubjectAttributes); len(attributes) > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithRequiredSubjectAttributes(attributes...))
+	}
+	if options.clientIDTrimSpace || options.clientIDIgnoreCase || options.subjectOrganization != "" || options.subjectOrganizationalUnit != "" {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithSubjectValidator(validationproxy.NewOrganizationSubjectValidator(
+			validationproxy.ClientIDNormalization{TrimSpace: options.clientIDTrimSpace, IgnoreCase: options.clientIDIgnoreCase},
+			validationproxy.SubjectOrganization{Organization: options.subjectOrganization, OrganizationalUnit: options.subjectOrganizationalUnit})))
+	}
+	if options.defaultSubjectOrganization != "" || options.defaultSubjectOrgUnit != "" {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithDefaultSubjectOrganization(options.defaultSubjectOrganization, options.defaultSubjectOrgUnit))
+	}
+	if options.logValidationStrategy {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithValidationStrategyLog())
+	}
+	var decisionLog http.Handler
+	if options.decisionLogSize > 0 {
+		decisions := validationproxy.NewDecisionLog(options.decisionLogSize)
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithDecisionLog(decisions))
+		decisionLog = decisions
+	}
+	var applicationCounters http.Handler
+	if options.appCountersIdleTimeout > 0 {
+		counters := validationproxy.NewApplicationCounters(options.appCountersIdleTimeout, options.appCountersMaxApplications)
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithApplicationCounters(counters))
+		applicationCounters = counters
+	}
+	if options.validateCloudEvents {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithEventValidator(validationproxy.NewCloudEventValidator(options.maxEventBodySize)))
+	}
+	if options.rewriteInternalRedirects {
+		proxyHandlerOptions = append(proxyHandlerOptions,
+			validationproxy.WithLocationRewrite(validationproxy.DestinationLegacyEvents, options.legacyEventsRedirectPrefix),
+			validationproxy.WithLocationRewrite(validationproxy.DestinationCloudEvents, options.cloudEventsRedirectPrefix))
+	}
+	if options.circuitBreakerThreshold > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions,
+			validationproxy.WithCircuitBreaker(validationproxy.DestinationLegacyEvents, options.circuitBreakerThreshold, options.circuitBreakerOpenTimeout),
+			validationproxy.WithCircuitBreaker(validationproxy.DestinationCloudEvents, options.circuitBreakerThreshold, options.circuitBreakerOpenTimeout))
+	}
+	if options.maxConcurrentRequests > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithMaxConcurrentRequests(options.maxConcurrentRequests))
+	}
+	if options.legacyEventsMaxConcurrent > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithMaxConcurrentDestinationRequests(validationproxy.DestinationLegacyEvents, options.legacyEventsMaxConcurrent))
+	}
+	if options.cloudEventsMaxConcurrent > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithMaxConcurrentDestinationRequests(validationproxy.DestinationCloudEvents, options.cloudEventsMaxConcurrent))
+	}
+	if options.trustedProxyHops >= 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithTrustedProxyHops(options.trustedProxyHops))
+	}
+	if len(pathRedactionPatterns) > 0 {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithPathRedaction(pathRedactionPatterns...))
+	}
+	if options.clientIDsConfigMapName != "" {
+		proxyHandlerOptions = append(proxyHandlerOptions, validationproxy.WithClientIDSource(
+			// the API reader gets the single ConfigMap, the cached client would list and watch all ConfigMaps of the cluster.
+			// The handler reuses the read client IDs for clientIDsCacheTTL, so the API server is not read on every request.
+			validationproxy.NewConfigMapClientIDSource(mgr.GetAPIReader(), options.clientIDsConfigMapNamespace, options.clientIDsConfigMapName,
+				validationproxy.WithFetchRetries(options.clientIDsFetchAttempts, options.clientIDsFetchBackoff))),
+			validationproxy.WithClientIDFetchTimeout(options.clientIDsFetchTimeout),
+			validationproxy.WithClientIDCacheTTL(options.clientIDsCacheTTL))
+	}
+
+	var proxyHandler validationproxy.ProxyHandler
+	var reloadingProxyHandler validationproxy.ReloadingProxyHandler
+	if options.proxyConfigFile != "" {
+		proxyConfig, err := validationproxy.LoadProxyConfig(options.proxyConfigFile)
+		if err != nil {
+			log.WithContext().Error("Unable to load proxy config: %s", err.Error())
+			os.Exit(1)
+		}
+		reloadingProxyHandler, err = validationproxy.NewReloadingProxyHandler(proxyConfig, idCache, log, proxyHandlerOptions...)
+		if err != nil {
+			log.WithContext().Error("Invalid proxy configuration: %s", err.Error())
+			os.Exit(1)
+		}
+		proxyHandler = reloadingProxyHandler
+	} else {
+		proxyHandler, err = validationproxy.NewProxyHandlerFromConfig(validationproxy.ProxyConfig{
+			EventingPublisherHost:   options.eventingPublisherHost,
+			EventingDestinationPath: options.eventingDestinationPath,
+		}, idCache, log, proxyHandlerOptions...)
+		if err != nil {
+			log.WithContext().Error("Invalid proxy configuration: %s", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	tracingMiddleware := tracing.NewTracingMiddleware(proxyHandler.ProxyAppConnectorRequests)
+
+	proxyServer := http.Server{
+		Handler: validationproxy.NewHandler(tracingMiddleware, options.proxyHealthPath),
+		Addr:    listenAddress(options.proxyListenAddress, options.proxyPort),
+	}
+
+	var ready atomic.Bool
+	externalServer := http.Server{
+		Handler: externalapi.NewHandler(ready.Load, decisionLog, applicationCounters),
+		Addr:    listenAddress(options.externalAPIListenAddress, options.externalAPIPort),
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	var g run.Group
 	addInterruptSignalToRunGroup(ctx, cancel, log, &g)
+	if applications := splitList(options.warmApplications); len(applications) > 0 {
+		cacheSync := controller.NewCacheSync(log, mgr.GetAPIReader(), idCache, "cache_warmer", options.appNamePlaceholder,
+			options.eventingPathPrefixV1, options.eventingPathPrefixV2, options.eventingPathPrefixEvents, cacheSyncOptions...)
+		go warmCache(ctx, log, cacheSync, applications, options.cacheWarmTimeout, &ready)
+	} else {
+		ready.Store(true)
+	}
 	addManagerToRunGroup(ctx, log, &g, mgr)
+	if reloadingProxyHandler != nil {
+		addProxyConfigWatcherToRunGroup(ctx, log, &g, options.proxyConfigFile, options.proxyConfigReloadInterval, reloadingProxyHandler)
+	}
 	addHttpServerToRunGroup(log, "proxy-server", &g, &proxyServer)
 	addHttpServerToRunGroup(log, "external-server", &g, &externalServer)
 
@@ -173,6 +326,17 @@ func addHttpServerToRunGroup(log *logger.Logger, name string, g *run.Group, srv
 	})
 }
 
+func warmCache(ctx context.Context, log *logger.Logger, cacheSync controller.CacheSync, applications []string, timeout time.Duration, ready *atomic.Bool) {
+	warmCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := controller.WarmCache(warmCtx, cacheSync, applications, cacheWarmRetryDelay); err != nil {
+		log.WithContext().Warnf("Reporting ready before loading all applications to the cache: %s", err.Error())
+	} else {
+		log.WithContext().Infof("Loaded %d applications to the cache", len(applications))
+	}
+	ready.Store(true)
+}
+
 func addManagerToRunGroup(ctx context.Context, log *logger.Logger, g *run.Group, mgr manager.Manager) {
 	g.Add(func() error {
 		defer log.WithContext().Infof("Manager finished")
@@ -181,6 +345,17 @@ func addManagerToRunGroup(ctx context.Context, log *logger.Logger, g *run.Group,
 	})
 }
 
+func addProxyConfigWatcherToRunGroup(ctx context.Context, log *logger.Logger, g *run.Group, path string, interval time.Duration, handler validationproxy.ReloadingProxyHandler) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	g.Add(func() error {
+		defer log.WithContext().Infof("Proxy config watcher finished")
+		validationproxy.WatchProxyConfig(watchCtx, path, interval, handler, log)
+		return nil
+	}, func(error) {
+		cancel()
+	})
+}
+
 func addInterruptSignalToRunGroup(ctx context.Context, cancel context.CancelFunc, log *logger.Logger, g *run.Group) {
 	g.Add(func() error {
 		c := make(chan os.Signal, 1)
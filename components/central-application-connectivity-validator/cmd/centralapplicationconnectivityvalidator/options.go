@@ -3,23 +3,85 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/validationproxy"
 	"github.com/vrischmann/envconfig"
 	"k8s.io/client-go/tools/clientcmd"
+	"net"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
 
 type args struct {
-	proxyPort                int
-	externalAPIPort          int
-	eventingPathPrefixV1     string
-	eventingPathPrefixV2     string
-	eventingPublisherHost    string
-	eventingPathPrefixEvents string
-	eventingDestinationPath  string
-	appNamePlaceholder       string
-	syncPeriod               time.Duration
+	proxyPort                   int
+	externalAPIPort             int
+	proxyListenAddress          string
+	externalAPIListenAddress    string
+	eventingPathPrefixV1        string
+	eventingPathPrefixV2        string
+	eventingPublisherHost       string
+	eventingPathPrefixEvents    string
+	eventingDestinationPath     string
+	eventsAPIRoutes             string
+	fallbackDestination         string
+	appNamePlaceholder          string
+	syncPeriod                  time.Duration
+	warmApplications            string
+	cacheWarmTimeout            time.Duration
+	maxConcurrentReconciles     int
+	subjectValidationMode       string
+	sanValidationMode           string
+	maxCertHeaderLength         int
+	sanURIPrefix                string
+	clientIDsConfigMapNamespace string
+	clientIDsConfigMapName      string
+	clientIDFetchFailurePolicy  string
+	clientIDsFetchAttempts      int
+	clientIDsFetchBackoff       time.Duration
+	clientIDsFetchTimeout       time.Duration
+	clientIDsCacheTTL           time.Duration
+	clientIDTrimSpace           bool
+	clientIDIgnoreCase          bool
+	pathRedactionPatterns       string
+	logSamplingRate             int
+	logValidationStrategy       bool
+	decisionLogSize             int
+	appCountersIdleTimeout      time.Duration
+	appCountersMaxApplications  int
+	legacyEventsResponseTimeout time.Duration
+	cloudEventsResponseTimeout  time.Duration
+	legacyEventsFlushInterval   time.Duration
+	cloudEventsFlushInterval    time.Duration
+	disabledDestinations        string
+	originalHostDestinations    string
+	trustedProxyHops            int
+	proxyHealthPath             string
+	legacyEventsAllowedMethods  string
+	cloudEventsAllowedMethods   string
+	metricsBindAddress          string
+	subjectDelimiter            string
+	subjectIdentityAttribute    string
+	subjectTenantAttribute      string
+	subjectGroupAttribute       string
+	subjectOrganization         string
+	subjectOrganizationalUnit   string
+	defaultSubjectOrganization  string
+	defaultSubjectOrgUnit       string
+	requiredSubjectAttributes   string
+	validateCloudEvents         bool
+	maxEventBodySize            int64
+	rewriteInternalRedirects    bool
+	legacyEventsRedirectPrefix  string
+	cloudEventsRedirectPrefix   string
+	maxConcurrentRequests       int
+	legacyEventsMaxConcurrent   int
+	cloudEventsMaxConcurrent    int
+	circuitBreakerThreshold     int
+	circuitBreakerOpenTimeout   time.Duration
+	proxyConfigFile             string
+	proxyConfigReloadInterval   time.Duration
 }
 
 type config struct {
@@ -35,13 +97,71 @@ type options struct {
 func parseOptions() (*options, error) {
 	proxyPort := flag.Int("proxyPort", 8081, "Proxy port.")
 	externalAPIPort := flag.Int("externalAPIPort", 8080, "External API port.")
+	proxyListenAddress := flag.String("proxyListenAddress", "", "IP address on which the proxy listens, empty means all interfaces")
+	externalAPIListenAddress := flag.String("externalAPIListenAddress", "", "IP address on which the external API listens, empty means all interfaces")
 	eventingPathPrefixV1 := flag.String("eventingPathPrefixV1", "/v1/events", "Prefix of paths that is directed to Kyma Eventing V1")
 	eventingPathPrefixV2 := flag.String("eventingPathPrefixV2", "/v2/events", "Prefix of paths that is directed to Kyma Eventing V2")
 	eventingPublisherHost := flag.String("eventingPublisherHost", "eventing-event-publisher-proxy.kyma-system", "Host (and port) of the Eventing Publisher")
 	eventingDestinationPath := flag.String("eventingDestinationPath", "/publish", "Path of the destination of the requests to the Eventing")
 	eventingPathPrefixEvents := flag.String("eventingPathPrefixEvents", "/events", "Prefix of paths that is directed to the Cloud Events based Eventing")
+	eventsAPIRoutes := flag.String("eventsAPIRoutes", "", "Comma-separated additional routes in the form destination=pathPrefix=host[/path], the path prefix contains appNamePlaceholder")
+	fallbackDestination := flag.String("fallbackDestination", "", "Destination of the requests whose path matches no route, empty answers them with 404")
 	appNamePlaceholder := flag.String("appNamePlaceholder", "%%APP_NAME%%", "Path URL placeholder used for an application name")
 	syncPeriod := flag.Duration("syncPeriod", 45*time.Second, "Sync period in seconds how often controller should periodically reconcile Application resource.")
+	warmApplications := flag.String("warmApplications", "", "Comma-separated applications loaded to the cache before the validator reports ready")
+	cacheWarmTimeout := flag.Duration("cacheWarmTimeout", 30*time.Second, "Time after which the validator reports ready even if some warmApplications are not loaded")
+	maxConcurrentReconciles := flag.Int("maxConcurrentReconciles", 1, "Number of Application resources reconciled in parallel")
+	subjectValidationMode := flag.String("subjectValidationMode", "any", "Mode of validating multiple certificate subjects, one of: any, all")
+	maxCertHeaderLength := flag.Int("maxCertHeaderLength", 0, "Maximum length in bytes of the X-Forwarded-Client-Cert header, longer headers are rejected, 0 means no limit")
+	sanValidationMode := flag.String("sanValidationMode", "disabled", "Mode of validating the URI Subject Alternative Names of the certificate, one of: disabled, required, alternative")
+	sanURIPrefix := flag.String("sanURIPrefix", "", "Prefix of the URI Subject Alternative Name followed by the application name")
+	subjectDelimiter := flag.String("subjectDelimiter", ",", "Delimiter separating the attributes of the certificate subject, empty means a comma")
+	subjectIdentityAttribute := flag.String("subjectIdentityAttribute", "CN", "Certificate subject attribute carrying the application identity")
+	subjectTenantAttribute := flag.String("subjectTenantAttribute", "O", "Certificate subject attribute carrying the tenant, read as the organization")
+	subjectGroupAttribute := flag.String("subjectGroupAttribute", "OU", "Certificate subject attribute carrying the group, read as the organizational unit")
+	subjectOrganization := flag.String("subjectOrganization", "", "Organization which the certificate subjects must present, empty does not validate the organization")
+	subjectOrganizationalUnit := flag.String("subjectOrganizationalUnit", "", "Organizational unit which the certificate subjects must present, empty does not validate the organizational unit")
+	defaultSubjectOrganization := flag.String("defaultSubjectOrganization", "", "Organization assumed for the certificate subjects presenting none, before subjectOrganization is validated")
+	defaultSubjectOrgUnit := flag.String("defaultSubjectOrganizationalUnit", "", "Organizational unit assumed for the certificate subjects presenting none, before subjectOrganizationalUnit is validated")
+	requiredSubjectAttributes := flag.String("requiredSubjectAttributes", "", "Comma-separated certificate subject attributes which must be present with non-empty values, for example O,OU")
+	clientIDsConfigMapNamespace := flag.String("clientIDsConfigMapNamespace", "", "Namespace of the ConfigMap with client IDs of applications not managed by Compass")
+	clientIDsConfigMapName := flag.String("clientIDsConfigMapName", "", "Name of the ConfigMap with client IDs of applications not managed by Compass")
+	clientIDsFetchAttempts := flag.Int("clientIDsFetchAttempts", 1, "Number of attempts of reading the client IDs ConfigMap after transient API server errors")
+	clientIDsFetchBackoff := flag.Duration("clientIDsFetchBackoff", 100*time.Millisecond, "Time to wait before the second attempt of reading the client IDs ConfigMap, doubled after every attempt")
+	clientIDsFetchTimeout := flag.Duration("clientIDsFetchTimeout", validationproxy.DefaultClientIDFetchTimeout, "Time after which reading the client IDs ConfigMap fails, including the retries")
+	clientIDsCacheTTL := flag.Duration("clientIDsCacheTTL", validationproxy.DefaultClientIDCacheTTL, "Time for which the client IDs read from the ConfigMap are reused for the requests of the application")
+	clientIDFetchFailurePolicy := flag.String("clientIDFetchFailurePolicy", "fail-closed", "Handling of requests whose client IDs cannot be fetched, one of: fail-closed, fail-open-to-cn")
+	clientIDTrimSpace := flag.Bool("clientIDTrimSpace", false, "Ignore the surrounding whitespace when matching the certificate Common Name with the client IDs")
+	clientIDIgnoreCase := flag.Bool("clientIDIgnoreCase", false, "Ignore the case when matching the certificate Common Name with the client IDs")
+	logSamplingRate := flag.Int("logSamplingRate", 1, "Informational logs are written for 1 in logSamplingRate requests, errors are always logged")
+	logValidationStrategy := flag.Bool("logValidationStrategy", false, "Log the strategy which approved the identity of each accepted request")
+	decisionLogSize := flag.Int("decisionLogSize", 0, "Number of the last proxy decisions served by the external API on /v1/decisions, 0 disables recording them")
+	appCountersIdleTimeout := flag.Duration("applicationCountersIdleTimeout", 0, "Time without requests after which an application is removed from the counters served by the external API on /v1/applications, 0 disables counting")
+	appCountersMaxApplications := flag.Int("applicationCountersMaxApplications", validationproxy.DefaultMaxApplicationCounters, "Maximum number of applications counted at the same time, new applications are not counted until idle ones are removed")
+	legacyEventsResponseTimeout := flag.Duration("legacyEventsResponseTimeout", 0, "Time to wait for the response headers of the legacy events destination, 0 means no timeout")
+	cloudEventsResponseTimeout := flag.Duration("cloudEventsResponseTimeout", 0, "Time to wait for the response headers of the cloud events destination, 0 means no timeout")
+	legacyEventsFlushInterval := flag.Duration("legacyEventsFlushInterval", 0, "Interval of flushing the legacy events responses to the client, 0 flushes only responses without known length")
+	cloudEventsFlushInterval := flag.Duration("cloudEventsFlushInterval", 0, "Interval of flushing the cloud events responses to the client, 0 flushes only responses without known length")
+	disabledDestinations := flag.String("disabledDestinations", "", "Comma-separated destinations to which requests are not proxied, any of: legacy-events, cloud-events")
+	originalHostDestinations := flag.String("originalHostDestinations", "", "Comma-separated destinations receiving the original Host header of the client, any of: legacy-events, cloud-events")
+	legacyEventsAllowedMethods := flag.String("legacyEventsAllowedMethods", "", "Comma-separated HTTP methods proxied to the legacy events destination, empty allows all methods")
+	cloudEventsAllowedMethods := flag.String("cloudEventsAllowedMethods", "", "Comma-separated HTTP methods proxied to the cloud events destination, empty allows all methods")
+	validateCloudEvents := flag.Bool("validateCloudEvents", false, "Reject events sent to the cloud events destination without the required CloudEvent attributes")
+	maxEventBodySize := flag.Int64("maxEventBodySize", validationproxy.DefaultMaxEventBodySize, "Maximum size in bytes of the structured events read for the validation, longer events are rejected")
+	rewriteInternalRedirects := flag.Bool("rewriteInternalRedirects", false, "Rewrite the Location headers of upstream redirects pointing at internal hosts")
+	legacyEventsRedirectPrefix := flag.String("legacyEventsRedirectPrefix", "", "Public path prefix of rewritten legacy events redirects, empty strips the Location header")
+	cloudEventsRedirectPrefix := flag.String("cloudEventsRedirectPrefix", "", "Public path prefix of rewritten cloud events redirects, empty strips the Location header")
+	maxConcurrentRequests := flag.Int("maxConcurrentRequests", 0, "Maximum number of requests proxied concurrently, 0 means no limit")
+	legacyEventsMaxConcurrent := flag.Int("legacyEventsMaxConcurrent", 0, "Maximum number of requests proxied concurrently to the legacy events destination, 0 means no limit")
+	cloudEventsMaxConcurrent := flag.Int("cloudEventsMaxConcurrent", 0, "Maximum number of requests proxied concurrently to the cloud events destination, 0 means no limit")
+	circuitBreakerThreshold := flag.Int("circuitBreakerThreshold", 0, "Number of consecutive failed requests after which proxying to the destination is stopped, 0 disables the circuit breaker")
+	circuitBreakerOpenTimeout := flag.Duration("circuitBreakerOpenTimeout", 30*time.Second, "Time for which proxying to the failing destination is stopped before probing its recovery")
+	proxyConfigFile := flag.String("proxyConfigFile", "", "YAML file with eventingPublisherHost and eventingDestinationPath reloaded on change, overrides the flags when set")
+	proxyConfigReloadInterval := flag.Duration("proxyConfigReloadInterval", 10*time.Second, "Interval of checking the proxy config file for changes")
+	metricsBindAddress := flag.String("metricsBindAddress", "0", "Address of the Prometheus metrics endpoint, 0 disables it")
+	proxyHealthPath := flag.String("proxyHealthPath", "/healthz", "Path of the proxy answered with 200 without certificate validation, empty disables it")
+	trustedProxyHops := flag.Int("trustedProxyHops", -1, "Number of trusted proxies whose X-Forwarded-For entries are forwarded, -1 forwards all entries")
+	pathRedactionPatterns := flag.String("pathRedactionPatterns", "", "Comma-separated regular expressions of request path segments redacted in the logs")
 
 	flag.Parse()
 
@@ -52,15 +172,73 @@ func parseOptions() (*options, error) {
 
 	return &options{
 		args: args{
-			proxyPort:                *proxyPort,
-			externalAPIPort:          *externalAPIPort,
-			eventingPathPrefixV1:     *eventingPathPrefixV1,
-			eventingPathPrefixV2:     *eventingPathPrefixV2,
-			eventingPublisherHost:    *eventingPublisherHost,
-			eventingPathPrefixEvents: *eventingPathPrefixEvents,
-			eventingDestinationPath:  *eventingDestinationPath,
-			appNamePlaceholder:       *appNamePlaceholder,
-			syncPeriod:               *syncPeriod,
+			proxyPort:                   *proxyPort,
+			externalAPIPort:             *externalAPIPort,
+			proxyListenAddress:          *proxyListenAddress,
+			externalAPIListenAddress:    *externalAPIListenAddress,
+			eventingPathPrefixV1:        *eventingPathPrefixV1,
+			eventingPathPrefixV2:        *eventingPathPrefixV2,
+			eventingPublisherHost:       *eventingPublisherHost,
+			eventingPathPrefixEvents:    *eventingPathPrefixEvents,
+			eventingDestinationPath:     *eventingDestinationPath,
+			eventsAPIRoutes:             *eventsAPIRoutes,
+			fallbackDestination:         *fallbackDestination,
+			appNamePlaceholder:          *appNamePlaceholder,
+			syncPeriod:                  *syncPeriod,
+			warmApplications:            *warmApplications,
+			cacheWarmTimeout:            *cacheWarmTimeout,
+			maxConcurrentReconciles:     *maxConcurrentReconciles,
+			subjectValidationMode:       *subjectValidationMode,
+			sanValidationMode:           *sanValidationMode,
+			maxCertHeaderLength:         *maxCertHeaderLength,
+			sanURIPrefix:                *sanURIPrefix,
+			subjectDelimiter:            *subjectDelimiter,
+			subjectIdentityAttribute:    *subjectIdentityAttribute,
+			subjectTenantAttribute:      *subjectTenantAttribute,
+			subjectGroupAttribute:       *subjectGroupAttribute,
+			subjectOrganization:         *subjectOrganization,
+			subjectOrganizationalUnit:   *subjectOrganizationalUnit,
+			defaultSubjectOrganization:  *defaultSubjectOrganization,
+			defaultSubjectOrgUnit:       *defaultSubjectOrgUnit,
+			requiredSubjectAttributes:   *requiredSubjectAttributes,
+			validateCloudEvents:         *validateCloudEvents,
+			maxEventBodySize:            *maxEventBodySize,
+			rewriteInternalRedirects:    *rewriteInternalRedirects,
+			legacyEventsRedirectPrefix:  *legacyEventsRedirectPrefix,
+			cloudEventsRedirectPrefix:   *cloudEventsRedirectPrefix,
+			maxConcurrentRequests:       *maxConcurrentRequests,
+			legacyEventsMaxConcurrent:   *legacyEventsMaxConcurrent,
+			cloudEventsMaxConcurrent:    *cloudEventsMaxConcurrent,
+			circuitBreakerThreshold:     *circuitBreakerThreshold,
+			circuitBreakerOpenTimeout:   *circuitBreakerOpenTimeout,
+			proxyConfigFile:             *proxyConfigFile,
+			proxyConfigReloadInterval:   *proxyConfigReloadInterval,
+			clientIDsConfigMapNamespace: *clientIDsConfigMapNamespace,
+			clientIDsConfigMapName:      *clientIDsConfigMapName,
+			clientIDFetchFailurePolicy:  *clientIDFetchFailurePolicy,
+			clientIDsFetchAttempts:      *clientIDsFetchAttempts,
+			clientIDsFetchBackoff:       *clientIDsFetchBackoff,
+			clientIDsFetchTimeout:       *clientIDsFetchTimeout,
+			clientIDsCacheTTL:           *clientIDsCacheTTL,
+			clientIDTrimSpace:           *clientIDTrimSpace,
+			clientIDIgnoreCase:          *clientIDIgnoreCase,
+			pathRedactionPatterns:       *pathRedactionPatterns,
+			logSamplingRate:             *logSamplingRate,
+			logValidationStrategy:       *logValidationStrategy,
+			decisionLogSize:             *decisionLogSize,
+			appCountersIdleTimeout:      *appCountersIdleTimeout,
+			appCountersMaxApplications:  *appCountersMaxApplications,
+			legacyEventsResponseTimeout: *legacyEventsResponseTimeout,
+			cloudEventsResponseTimeout:  *cloudEventsResponseTimeout,
+			legacyEventsFlushInterval:   *legacyEventsFlushInterval,
+			cloudEventsFlushInterval:    *cloudEventsFlushInterval,
+			disabledDestinations:        *disabledDestinations,
+			originalHostDestinations:    *originalHostDestinations,
+			trustedProxyHops:            *trustedProxyHops,
+			proxyHealthPath:             *proxyHealthPath,
+			legacyEventsAllowedMethods:  *legacyEventsAllowedMethods,
+			cloudEventsAllowedMethods:   *cloudEventsAllowedMethods,
+			metricsBindAddress:          *metricsBindAddress,
 		},
 		config: c,
 	}, nil
@@ -68,30 +246,245 @@ func parseOptions() (*options, error) {
 
 func (o *options) String() string {
 	return fmt.Sprintf("--proxyPort=%d --externalAPIPort=%d "+
+		"--proxyListenAddress=%s --externalAPIListenAddress=%s "+
 		"--eventingPathPrefixV1=%s --eventingPathPrefixV2=%s "+
 		"--eventingPathPrefixEvents=%s --eventingPublisherHost=%s "+
 		"--eventingDestinationPath=%s "+
 		"--appNamePlaceholder=%s "+
-		"--syncPeriod=%d APP_LOG_FORMAT=%s APP_LOG_LEVEL=%s KUBECONFIG=%s",
+		"--syncPeriod=%d --warmApplications=%s --cacheWarmTimeout=%s --maxConcurrentReconciles=%d --subjectValidationMode=%s --subjectDelimiter=%s "+
+		"--subjectIdentityAttribute=%s --subjectTenantAttribute=%s --subjectGroupAttribute=%s --requiredSubjectAttributes=%s "+
+		"--subjectOrganization=%s --subjectOrganizationalUnit=%s --defaultSubjectOrganization=%s --defaultSubjectOrganizationalUnit=%s "+
+		"--sanValidationMode=%s --sanURIPrefix=%s --maxCertHeaderLength=%d "+
+		"--clientIDsConfigMapNamespace=%s --clientIDsConfigMapName=%s --clientIDFetchFailurePolicy=%s "+
+		"--clientIDsFetchAttempts=%d --clientIDsFetchBackoff=%s --clientIDsFetchTimeout=%s --clientIDsCacheTTL=%s "+
+		"--clientIDTrimSpace=%t --clientIDIgnoreCase=%t "+
+		"--pathRedactionPatterns=%s --logSamplingRate=%d --logValidationStrategy=%t --decisionLogSize=%d --applicationCountersIdleTimeout=%s --applicationCountersMaxApplications=%d --eventsAPIRoutes=%s --fallbackDestination=%s "+
+		"--legacyEventsResponseTimeout=%s --cloudEventsResponseTimeout=%s "+
+		"--legacyEventsFlushInterval=%s --cloudEventsFlushInterval=%s "+
+		"--disabledDestinations=%s --originalHostDestinations=%s --trustedProxyHops=%d --proxyHealthPath=%s "+
+		"--legacyEventsAllowedMethods=%s --cloudEventsAllowedMethods=%s "+
+		"--metricsBindAddress=%s --validateCloudEvents=%t --maxEventBodySize=%d "+
+		"--rewriteInternalRedirects=%t --legacyEventsRedirectPrefix=%s --cloudEventsRedirectPrefix=%s "+
+		"--maxConcurrentRequests=%d --legacyEventsMaxConcurrent=%d --cloudEventsMaxConcurrent=%d "+
+		"--circuitBreakerThreshold=%d --circuitBreakerOpenTimeout=%s "+
+		"--proxyConfigFile=%s --proxyConfigReloadInterval=%s "+
+		"APP_LOG_FORMAT=%s APP_LOG_LEVEL=%s KUBECONFIG=%s",
 		o.proxyPort, o.externalAPIPort,
+		o.proxyListenAddress, o.externalAPIListenAddress,
 		o.eventingPathPrefixV1, o.eventingPathPrefixV2, o.eventingPathPrefixEvents,
 		o.eventingPublisherHost, o.eventingDestinationPath,
 		o.appNamePlaceholder,
-		o.syncPeriod, o.LogFormat, o.LogLevel, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
+		o.syncPeriod, o.warmApplications, o.cacheWarmTimeout, o.maxConcurrentReconciles, o.subjectValidationMode, o.subjectDelimiter,
+		o.subjectIdentityAttribute, o.subjectTenantAttribute, o.subjectGroupAttribute, o.requiredSubjectAttributes,
+		o.subjectOrganization, o.subjectOrganizationalUnit, o.defaultSubjectOrganization, o.defaultSubjectOrgUnit,
+		o.sanValidationMode, o.sanURIPrefix, o.maxCertHeaderLength,
+		o.clientIDsConfigMapNamespace, o.clientIDsConfigMapName, o.clientIDFetchFailurePolicy,
+		o.clientIDsFetchAttempts, o.clientIDsFetchBackoff, o.clientIDsFetchTimeout, o.clientIDsCacheTTL,
+		o.clientIDTrimSpace, o.clientIDIgnoreCase,
+		o.pathRedactionPatterns, o.logSamplingRate, o.logValidationStrategy, o.decisionLogSize, o.appCountersIdleTimeout, o.appCountersMaxApplications, o.eventsAPIRoutes, o.fallbackDestination,
+		o.legacyEventsResponseTimeout, o.cloudEventsResponseTimeout,
+		o.legacyEventsFlushInterval, o.cloudEventsFlushInterval,
+		o.disabledDestinations, o.originalHostDestinations, o.trustedProxyHops, o.proxyHealthPath,
+		o.legacyEventsAllowedMethods, o.cloudEventsAllowedMethods,
+		o.metricsBindAddress, o.validateCloudEvents, o.maxEventBodySize,
+		o.rewriteInternalRedirects, o.legacyEventsRedirectPrefix, o.cloudEventsRedirectPrefix,
+		o.maxConcurrentRequests, o.legacyEventsMaxConcurrent, o.cloudEventsMaxConcurrent,
+		o.circuitBreakerThreshold, o.circuitBreakerOpenTimeout,
+		o.proxyConfigFile, o.proxyConfigReloadInterval, o.LogFormat, o.LogLevel, os.Getenv(clientcmd.RecommendedConfigPathEnvVar))
 }
 
 func (o *options) validate() error {
+	for _, address := range []string{o.proxyListenAddress, o.externalAPIListenAddress} {
+		if address != "" && net.ParseIP(address) == nil {
+			return fmt.Errorf("listen address '%s' should be an IP address", address)
+		}
+	}
+	// port 0 selects a free port, so only fixed ports can collide
+	if o.proxyPort != 0 && listenAddress(o.proxyListenAddress, o.proxyPort) == listenAddress(o.externalAPIListenAddress, o.externalAPIPort) {
+		return fmt.Errorf("proxy and external API should listen on different addresses, both use '%s'", listenAddress(o.proxyListenAddress, o.proxyPort))
+	}
+	if o.subjectValidationMode != "" && o.subjectValidationMode != "any" && o.subjectValidationMode != "all" {
+		return fmt.Errorf("subjectValidationMode '%s' should be one of: any, all", o.subjectValidationMode)
+	}
+	for _, attribute := range append([]string{o.subjectIdentityAttribute, o.subjectTenantAttribute, o.subjectGroupAttribute}, splitList(o.requiredSubjectAttributes)...) {
+		if strings.ContainsAny(attribute, "=\"") || strings.TrimSpace(attribute) != attribute {
+			return fmt.Errorf("subject attribute '%s' should be an attribute name", attribute)
+		}
+	}
+	if (o.clientIDsConfigMapNamespace == "") != (o.clientIDsConfigMapName == "") {
+		return fmt.Errorf("clientIDsConfigMapNamespace '%s' and clientIDsConfigMapName '%s' should be set together", o.clientIDsConfigMapNamespace, o.clientIDsConfigMapName)
+	}
+	if o.clientIDFetchFailurePolicy != "" && o.clientIDFetchFailurePolicy != string(validationproxy.ClientIDFetchFailurePolicyFailClosed) && o.clientIDFetchFailurePolicy != string(validationproxy.ClientIDFetchFailurePolicyFailOpenToCN) {
+		return fmt.Errorf("clientIDFetchFailurePolicy '%s' should be one of: %s, %s", o.clientIDFetchFailurePolicy, validationproxy.ClientIDFetchFailurePolicyFailClosed, validationproxy.ClientIDFetchFailurePolicyFailOpenToCN)
+	}
+	if o.clientIDsFetchAttempts < 0 || o.clientIDsFetchBackoff < 0 {
+		return fmt.Errorf("clientIDsFetchAttempts '%d' and clientIDsFetchBackoff '%s' should not be negative", o.clientIDsFetchAttempts, o.clientIDsFetchBackoff)
+	}
+	if o.clientIDsConfigMapName != "" && o.clientIDsFetchTimeout <= 0 {
+		return fmt.Errorf("clientIDsFetchTimeout '%s' should be positive", o.clientIDsFetchTimeout)
+	}
+	if o.clientIDsConfigMapName != "" && o.clientIDsCacheTTL <= 0 {
+		return fmt.Errorf("clientIDsCacheTTL '%s' should be positive", o.clientIDsCacheTTL)
+	}
+	if o.legacyEventsResponseTimeout < 0 || o.cloudEventsResponseTimeout < 0 {
+		return fmt.Errorf("legacyEventsResponseTimeout '%s' and cloudEventsResponseTimeout '%s' should not be negative", o.legacyEventsResponseTimeout, o.cloudEventsResponseTimeout)
+	}
+	if o.maxConcurrentRequests < 0 || o.legacyEventsMaxConcurrent < 0 || o.cloudEventsMaxConcurrent < 0 {
+		return fmt.Errorf("maxConcurrentRequests '%d', legacyEventsMaxConcurrent '%d', and cloudEventsMaxConcurrent '%d' should not be negative", o.maxConcurrentRequests, o.legacyEventsMaxConcurrent, o.cloudEventsMaxConcurrent)
+	}
+	if o.circuitBreakerThreshold < 0 {
+		return fmt.Errorf("circuitBreakerThreshold '%d' should not be negative", o.circuitBreakerThreshold)
+	}
+	if o.circuitBreakerThreshold > 0 && o.circuitBreakerOpenTimeout <= 0 {
+		return fmt.Errorf("circuitBreakerOpenTimeout '%s' should be positive", o.circuitBreakerOpenTimeout)
+	}
+	if o.proxyConfigFile != "" && o.proxyConfigReloadInterval <= 0 {
+		return fmt.Errorf("proxyConfigReloadInterval '%s' should be positive", o.proxyConfigReloadInterval)
+	}
+	if o.maxCertHeaderLength < 0 {
+		return fmt.Errorf("maxCertHeaderLength '%d' should not be negative", o.maxCertHeaderLength)
+	}
+	if o.defaultSubjectOrganization != "" && o.subjectOrganization == "" {
+		return fmt.Errorf("defaultSubjectOrganization '%s' should be set only with subjectOrganization", o.defaultSubjectOrganization)
+	}
+	if o.defaultSubjectOrgUnit != "" && o.subjectOrganizationalUnit == "" {
+		return fmt.Errorf("defaultSubjectOrganizationalUnit '%s' should be set only with subjectOrganizationalUnit", o.defaultSubjectOrgUnit)
+	}
+	if o.validateCloudEvents && o.maxEventBodySize <= 0 {
+		return fmt.Errorf("maxEventBodySize '%d' should be positive", o.maxEventBodySize)
+	}
+	if o.logSamplingRate < 0 {
+		return fmt.Errorf("logSamplingRate '%d' should not be negative", o.logSamplingRate)
+	}
+	if o.decisionLogSize < 0 {
+		return fmt.Errorf("decisionLogSize '%d' should not be negative", o.decisionLogSize)
+	}
+	if o.appCountersIdleTimeout < 0 {
+		return fmt.Errorf("applicationCountersIdleTimeout '%s' should not be negative", o.appCountersIdleTimeout)
+	}
+	if o.appCountersIdleTimeout > 0 && o.appCountersMaxApplications <= 0 {
+		return fmt.Errorf("applicationCountersMaxApplications '%d' should be positive", o.appCountersMaxApplications)
+	}
+	if len(splitList(o.warmApplications)) > 0 && o.cacheWarmTimeout <= 0 {
+		return fmt.Errorf("cacheWarmTimeout '%s' should be positive", o.cacheWarmTimeout)
+	}
+	if o.maxConcurrentReconciles < 0 {
+		return fmt.Errorf("maxConcurrentReconciles '%d' should not be negative", o.maxConcurrentReconciles)
+	}
+	if o.sanValidationMode != "" && o.sanValidationMode != "disabled" && o.sanValidationMode != "required" && o.sanValidationMode != "alternative" {
+		return fmt.Errorf("sanValidationMode '%s' should be one of: disabled, required, alternative", o.sanValidationMode)
+	}
+	if o.sanValidationMode != "" && o.sanValidationMode != "disabled" && o.sanURIPrefix == "" {
+		return fmt.Errorf("sanURIPrefix should be set when sanValidationMode is '%s'", o.sanValidationMode)
+	}
+	if o.proxyHealthPath != "" && (!strings.HasPrefix(o.proxyHealthPath, "/") || strings.HasSuffix(o.proxyHealthPath, "/")) {
+		return fmt.Errorf("proxyHealthPath '%s' should start and must not end with '/'", o.proxyHealthPath)
+	}
+	for _, destination := range splitList(o.disabledDestinations) {
+		if destination != string(validationproxy.DestinationLegacyEvents) && destination != string(validationproxy.DestinationCloudEvents) {
+			return fmt.Errorf("disabledDestinations '%s' should contain only: %s, %s", o.disabledDestinations, validationproxy.DestinationLegacyEvents, validationproxy.DestinationCloudEvents)
+		}
+	}
+	for _, destination := range splitList(o.originalHostDestinations) {
+		if destination != string(validationproxy.DestinationLegacyEvents) && destination != string(validationproxy.DestinationCloudEvents) {
+			return fmt.Errorf("originalHostDestinations '%s' should contain only: %s, %s", o.originalHostDestinations, validationproxy.DestinationLegacyEvents, validationproxy.DestinationCloudEvents)
+		}
+	}
+	if _, err := parsePathRedactionPatterns(o.pathRedactionPatterns); err != nil {
+		return fmt.Errorf("pathRedactionPatterns '%s' should contain valid regular expressions: %s", o.pathRedactionPatterns, err)
+	}
+	routes, err := parseEventsAPIRoutes(o.eventsAPIRoutes, o.appNamePlaceholder)
+	if err != nil {
+		return fmt.Errorf("eventsAPIRoutes '%s' should contain valid routes: %s", o.eventsAPIRoutes, err)
+	}
+	if o.fallbackDestination != "" && !isKnownDestination(validationproxy.Destination(o.fallbackDestination), routes) {
+		return fmt.Errorf("fallbackDestination '%s' should be %s, %s, or a destination of eventsAPIRoutes", o.fallbackDestination, validationproxy.DestinationLegacyEvents, validationproxy.DestinationCloudEvents)
+	}
 	if o.appNamePlaceholder == "" {
 		return nil
 	}
-	if !strings.Contains(o.eventingPathPrefixV1, o.appNamePlaceholder) {
-		return fmt.Errorf("eventingPathPrefixV1 '%s' should contain appNamePlaceholder '%s'", o.eventingPathPrefixV1, o.appNamePlaceholder)
+	if !strings.HasPrefix(o.eventingPathPrefixV1, "/") || !strings.Contains(o.eventingPathPrefixV1, o.appNamePlaceholder) {
+		return fmt.Errorf("eventingPathPrefixV1 '%s' should start with / and contain appNamePlaceholder '%s'", o.eventingPathPrefixV1, o.appNamePlaceholder)
 	}
-	if !strings.Contains(o.eventingPathPrefixV2, o.appNamePlaceholder) {
-		return fmt.Errorf("eventingPathPrefixV2 '%s' should contain appNamePlaceholder '%s'", o.eventingPathPrefixV2, o.appNamePlaceholder)
+	if !strings.HasPrefix(o.eventingPathPrefixV2, "/") || !strings.Contains(o.eventingPathPrefixV2, o.appNamePlaceholder) {
+		return fmt.Errorf("eventingPathPrefixV2 '%s' should start with / and contain appNamePlaceholder '%s'", o.eventingPathPrefixV2, o.appNamePlaceholder)
 	}
-	if !strings.Contains(o.eventingPathPrefixEvents, o.appNamePlaceholder) {
-		return fmt.Errorf("eventingPathPrefixEvents '%s' should contain appNamePlaceholder '%s'", o.eventingPathPrefixEvents, o.appNamePlaceholder)
+	if !strings.HasPrefix(o.eventingPathPrefixEvents, "/") || !strings.Contains(o.eventingPathPrefixEvents, o.appNamePlaceholder) {
+		return fmt.Errorf("eventingPathPrefixEvents '%s' should start with / and contain appNamePlaceholder '%s'", o.eventingPathPrefixEvents, o.appNamePlaceholder)
 	}
 	return nil
 }
+
+// listenAddress joins the IP address and the port of a server, the empty IP address listens on all interfaces
+func listenAddress(ip string, port int) string {
+	return net.JoinHostPort(ip, strconv.Itoa(port))
+}
+
+func parsePathRedactionPatterns(patterns string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, pattern := range splitList(patterns) {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, compiled)
+	}
+	return result, nil
+}
+
+func parseEventsAPIRoutes(routes, appNamePlaceholder string) ([]validationproxy.EventsAPIRoute, error) {
+	var result []validationproxy.EventsAPIRoute
+	for _, route := range splitList(routes) {
+		parts := strings.SplitN(route, "=", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("route '%s' should have the form destination=pathPrefix=host[/path]", route)
+		}
+
+		destination := validationproxy.Destination(parts[0])
+		if destination == "" || destination == validationproxy.DestinationLegacyEvents || destination == validationproxy.DestinationCloudEvents {
+			return nil, fmt.Errorf("route '%s' should name a new destination", route)
+		}
+		if !strings.HasPrefix(parts[1], "/") || !strings.Contains(parts[1], appNamePlaceholder) {
+			return nil, fmt.Errorf("path prefix of route '%s' should start with / and contain appNamePlaceholder '%s'", route, appNamePlaceholder)
+		}
+
+		host, path, _ := strings.Cut(parts[2], "/")
+		if host == "" {
+			return nil, fmt.Errorf("route '%s' should contain the destination host", route)
+		}
+		if path != "" {
+			path = "/" + path
+		}
+
+		result = append(result, validationproxy.EventsAPIRoute{
+			Destination:        destination,
+			PathPrefix:         parts[1],
+			AppNamePlaceholder: appNamePlaceholder,
+			DestinationHost:    host,
+			DestinationPath:    path,
+		})
+	}
+	return result, nil
+}
+
+func isKnownDestination(destination validationproxy.Destination, routes []validationproxy.EventsAPIRoute) bool {
+	if destination == validationproxy.DestinationLegacyEvents || destination == validationproxy.DestinationCloudEvents {
+		return true
+	}
+	for _, route := range routes {
+		if route.Destination == destination {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
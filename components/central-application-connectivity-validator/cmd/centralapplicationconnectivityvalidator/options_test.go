@@ -74,6 +74,529 @@ func TestOptionsValidation(t *testing.T) {
 				syncPeriod:               121 * time.Second,
 			},
 		},
+		{
+			name:  "subjectValidationMode is set to all",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				subjectValidationMode:    "all",
+			},
+		},
+		{
+			name:  "invalid subjectValidationMode",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				subjectValidationMode:    "some",
+			},
+		},
+		{
+			name:  "negative cloudEventsResponseTimeout",
+			valid: false,
+			args: args{
+				appNamePlaceholder:         "%%APP_NAME%%",
+				eventingPathPrefixV1:       "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:       "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:   "/%%APP_NAME%%/events",
+				cloudEventsResponseTimeout: -time.Second,
+			},
+		},
+		{
+			name:  "proxyHealthPath ending with slash",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				proxyHealthPath:          "/healthz/",
+			},
+		},
+		{
+			name:  "sanValidationMode is set to alternative",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				sanValidationMode:        "alternative",
+				sanURIPrefix:             "spiffe://cluster.local/applications/",
+			},
+		},
+		{
+			name:  "sanValidationMode without sanURIPrefix",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				sanValidationMode:        "required",
+			},
+		},
+		{
+			name:  "proxy and external API listen on separate interfaces",
+			valid: true,
+			args: args{
+				proxyPort:                8081,
+				externalAPIPort:          8080,
+				proxyListenAddress:       "0.0.0.0",
+				externalAPIListenAddress: "127.0.0.1",
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+			},
+		},
+		{
+			name:  "proxy and external API listen on the same address",
+			valid: false,
+			args: args{
+				proxyPort:                8080,
+				externalAPIPort:          8080,
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+			},
+		},
+		{
+			name:  "listen address with port",
+			valid: false,
+			args: args{
+				proxyPort:                8081,
+				externalAPIPort:          8080,
+				externalAPIListenAddress: "127.0.0.1:8080",
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+			},
+		},
+		{
+			name:  "subject attributes are set",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				subjectIdentityAttribute: "OU",
+				subjectTenantAttribute:   "serialNumber",
+				subjectGroupAttribute:    "CN",
+			},
+		},
+		{
+			name:  "subject attribute with value",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				subjectIdentityAttribute: "OU=app",
+			},
+		},
+		{
+			name:  "negative decisionLogSize",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				decisionLogSize:          -1,
+			},
+		},
+		{
+			name:  "negative applicationCountersIdleTimeout",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				appCountersIdleTimeout:   -time.Second,
+			},
+		},
+		{
+			name:  "requiredSubjectAttributes are set",
+			valid: true,
+			args: args{
+				appNamePlaceholder:        "%%APP_NAME%%",
+				eventingPathPrefixV1:      "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:      "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:  "/%%APP_NAME%%/events",
+				requiredSubjectAttributes: "O,OU",
+			},
+		},
+		{
+			name:  "requiredSubjectAttributes with value",
+			valid: false,
+			args: args{
+				appNamePlaceholder:        "%%APP_NAME%%",
+				eventingPathPrefixV1:      "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:      "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:  "/%%APP_NAME%%/events",
+				requiredSubjectAttributes: "O=Organization,OU",
+			},
+		},
+		{
+			name:  "clientIDsConfigMapName without clientIDsFetchTimeout",
+			valid: false,
+			args: args{
+				appNamePlaceholder:          "%%APP_NAME%%",
+				eventingPathPrefixV1:        "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:        "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:    "/%%APP_NAME%%/events",
+				clientIDsConfigMapNamespace: "kyma-system",
+				clientIDsConfigMapName:      "client-ids",
+			},
+		},
+		{
+			name:  "clientIDsConfigMapName without clientIDsCacheTTL",
+			valid: false,
+			args: args{
+				appNamePlaceholder:          "%%APP_NAME%%",
+				eventingPathPrefixV1:        "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:        "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:    "/%%APP_NAME%%/events",
+				clientIDsConfigMapNamespace: "kyma-system",
+				clientIDsConfigMapName:      "client-ids",
+				clientIDsFetchTimeout:       time.Second,
+			},
+		},
+		{
+			name:  "clientIDsConfigMapName with clientIDsFetchTimeout and clientIDsCacheTTL",
+			valid: true,
+			args: args{
+				appNamePlaceholder:          "%%APP_NAME%%",
+				eventingPathPrefixV1:        "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:        "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:    "/%%APP_NAME%%/events",
+				clientIDsConfigMapNamespace: "kyma-system",
+				clientIDsConfigMapName:      "client-ids",
+				clientIDsFetchTimeout:       time.Second,
+				clientIDsCacheTTL:           time.Minute,
+			},
+		},
+		{
+			name:  "validateCloudEvents without maxEventBodySize",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				validateCloudEvents:      true,
+			},
+		},
+		{
+			name:  "applicationCountersIdleTimeout without applicationCountersMaxApplications",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				appCountersIdleTimeout:   time.Minute,
+			},
+		},
+		{
+			name:  "defaultSubjectOrganization with subjectOrganization",
+			valid: true,
+			args: args{
+				appNamePlaceholder:         "%%APP_NAME%%",
+				eventingPathPrefixV1:       "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:       "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:   "/%%APP_NAME%%/events",
+				subjectOrganization:        "Organization",
+				defaultSubjectOrganization: "Organization",
+			},
+		},
+		{
+			name:  "defaultSubjectOrganizationalUnit without subjectOrganizationalUnit",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				subjectOrganization:      "Organization",
+				defaultSubjectOrgUnit:    "OrgUnit",
+			},
+		},
+		{
+			name:  "negative clientIDsFetchAttempts",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				clientIDsFetchAttempts:   -1,
+			},
+		},
+		{
+			name:  "warmApplications without cacheWarmTimeout",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				warmApplications:         "app-1,app-2",
+			},
+		},
+		{
+			name:  "clientIDFetchFailurePolicy is set to fail-open-to-cn",
+			valid: true,
+			args: args{
+				appNamePlaceholder:         "%%APP_NAME%%",
+				eventingPathPrefixV1:       "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:       "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:   "/%%APP_NAME%%/events",
+				clientIDFetchFailurePolicy: "fail-open-to-cn",
+			},
+		},
+		{
+			name:  "unknown clientIDFetchFailurePolicy",
+			valid: false,
+			args: args{
+				appNamePlaceholder:         "%%APP_NAME%%",
+				eventingPathPrefixV1:       "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:       "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:   "/%%APP_NAME%%/events",
+				clientIDFetchFailurePolicy: "fail-open",
+			},
+		},
+		{
+			name:  "eventingPathPrefixV2 without leading slash",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+			},
+		},
+		{
+			name:  "negative logSamplingRate",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				logSamplingRate:          -1,
+			},
+		},
+		{
+			name:  "negative maxConcurrentReconciles",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				maxConcurrentReconciles:  -1,
+			},
+		},
+		{
+			name:  "negative legacyEventsMaxConcurrent",
+			valid: false,
+			args: args{
+				appNamePlaceholder:        "%%APP_NAME%%",
+				eventingPathPrefixV1:      "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:      "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents:  "/%%APP_NAME%%/events",
+				legacyEventsMaxConcurrent: -1,
+			},
+		},
+		{
+			name:  "circuitBreakerThreshold without circuitBreakerOpenTimeout",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				circuitBreakerThreshold:  5,
+			},
+		},
+		{
+			name:  "proxyConfigFile without proxyConfigReloadInterval",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				proxyConfigFile:          "/etc/proxy/config.yaml",
+			},
+		},
+		{
+			name:  "valid disabledDestinations",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				disabledDestinations:     "legacy-events",
+			},
+		},
+		{
+			name:  "unknown disabledDestinations",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				disabledDestinations:     "legacy-events,app-registry",
+			},
+		},
+		{
+			name:  "unknown originalHostDestinations",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				originalHostDestinations: "cloud-events,app-registry",
+			},
+		},
+		{
+			name:  "fallbackDestination naming a route",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				eventsAPIRoutes:          "catch-all=/%%APP_NAME%%/catch-all=catch-all.kyma-system:8080",
+				fallbackDestination:      "catch-all",
+			},
+		},
+		{
+			name:  "unknown fallbackDestination",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				fallbackDestination:      "app-registry",
+			},
+		},
+		{
+			name:  "valid eventsAPIRoutes",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				eventsAPIRoutes:          "v3-events=/%%APP_NAME%%/v3/events=eventing-v3.kyma-system:8080/publish",
+			},
+		},
+		{
+			name:  "eventsAPIRoutes overriding a default destination",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				eventsAPIRoutes:          "cloud-events=/%%APP_NAME%%/v3/events=eventing-v3.kyma-system:8080",
+			},
+		},
+		{
+			name:  "eventsAPIRoutes without appNamePlaceholder",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				eventsAPIRoutes:          "v3-events=/v3/events=eventing-v3.kyma-system:8080",
+			},
+		},
+		{
+			name:  "malformed eventsAPIRoutes when appNamePlaceholder is empty",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "",
+				eventingPathPrefixV1:     "/app1/v1/events",
+				eventingPathPrefixV2:     "/app1/v2/events",
+				eventingPathPrefixEvents: "/app1/events",
+				eventsAPIRoutes:          "v3-events=/v3/events",
+			},
+		},
+		{
+			name:  "eventsAPIRoutes when appNamePlaceholder is empty",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "",
+				eventingPathPrefixV1:     "/app1/v1/events",
+				eventingPathPrefixV2:     "/app1/v2/events",
+				eventingPathPrefixEvents: "/app1/events",
+				eventsAPIRoutes:          "v3-events=/app1/v3/events=eventing-v3.kyma-system:8080",
+			},
+		},
+		{
+			name:  "unknown fallbackDestination when appNamePlaceholder is empty",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "",
+				eventingPathPrefixV1:     "/app1/v1/events",
+				eventingPathPrefixV2:     "/app1/v2/events",
+				eventingPathPrefixEvents: "/app1/events",
+				fallbackDestination:      "unknown",
+			},
+		},
+		{
+			name:  "fallbackDestination when appNamePlaceholder is empty",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "",
+				eventingPathPrefixV1:     "/app1/v1/events",
+				eventingPathPrefixV2:     "/app1/v2/events",
+				eventingPathPrefixEvents: "/app1/events",
+				fallbackDestination:      "legacy-events",
+			},
+		},
+		{
+			name:  "valid pathRedactionPatterns",
+			valid: true,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				pathRedactionPatterns:    "[0-9a-f]{8}-[0-9a-f-]{27},secret-[a-z]+",
+			},
+		},
+		{
+			name:  "invalid pathRedactionPatterns",
+			valid: false,
+			args: args{
+				appNamePlaceholder:       "%%APP_NAME%%",
+				eventingPathPrefixV1:     "/%%APP_NAME%%/v1/events",
+				eventingPathPrefixV2:     "/%%APP_NAME%%/v2/events",
+				eventingPathPrefixEvents: "/%%APP_NAME%%/events",
+				pathRedactionPatterns:    "[0-9",
+			},
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -87,3 +610,9 @@ func TestOptionsValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestListenAddress(t *testing.T) {
+	assert.Equal(t, ":8081", listenAddress("", 8081))
+	assert.Equal(t, "127.0.0.1:8080", listenAddress("127.0.0.1", 8080))
+	assert.Equal(t, "[::1]:8080", listenAddress("::1", 8080))
+}
@@ -3,22 +3,32 @@ package apperrors
 import "fmt"
 
 const (
-	CodeInternal      = 1
-	CodeNotFound      = 2
-	CodeAlreadyExists = 3
-	CodeWrongInput    = 4
-	CodeForbidden     = 5
-	CodeBadRequest    = 6
+	CodeInternal         = 1
+	CodeNotFound         = 2
+	CodeAlreadyExists    = 3
+	CodeWrongInput       = 4
+	CodeForbidden        = 5
+	CodeBadRequest       = 6
+	CodeMethodNotAllowed = 7
+	CodeUnavailable      = 8
+	CodeUnauthorized     = 9
+	CodeHeaderTooLarge   = 10
+	CodePayloadTooLarge  = 11
+	CodeBadGateway       = 12
+	CodeGatewayTimeout   = 13
 )
 
 type AppError interface {
+	WithErrorCode(errorCode string) AppError
 	Code() int
+	ErrorCode() string
 	Error() string
 }
 
 type appError struct {
-	code    int
-	message string
+	code      int
+	errorCode string
+	message   string
 }
 
 func errorf(code int, format string, a ...interface{}) AppError {
@@ -49,10 +59,48 @@ func BadRequest(format string, a ...interface{}) AppError {
 	return errorf(CodeBadRequest, format, a...)
 }
 
+func MethodNotAllowed(format string, a ...interface{}) AppError {
+	return errorf(CodeMethodNotAllowed, format, a...)
+}
+
+func Unavailable(format string, a ...interface{}) AppError {
+	return errorf(CodeUnavailable, format, a...)
+}
+
+func Unauthorized(format string, a ...interface{}) AppError {
+	return errorf(CodeUnauthorized, format, a...)
+}
+
+func HeaderTooLarge(format string, a ...interface{}) AppError {
+	return errorf(CodeHeaderTooLarge, format, a...)
+}
+
+func PayloadTooLarge(format string, a ...interface{}) AppError {
+	return errorf(CodePayloadTooLarge, format, a...)
+}
+
+func BadGateway(format string, a ...interface{}) AppError {
+	return errorf(CodeBadGateway, format, a...)
+}
+
+func GatewayTimeout(format string, a ...interface{}) AppError {
+	return errorf(CodeGatewayTimeout, format, a...)
+}
+
+// WithErrorCode returns a copy of the error carrying the machine-readable error code
+func (ae appError) WithErrorCode(errorCode string) AppError {
+	ae.errorCode = errorCode
+	return ae
+}
+
 func (ae appError) Code() int {
 	return ae.code
 }
 
+func (ae appError) ErrorCode() string {
+	return ae.errorCode
+}
+
 func (ae appError) Error() string {
 	return ae.message
 }
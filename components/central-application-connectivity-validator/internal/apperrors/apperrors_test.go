@@ -14,6 +14,13 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, CodeAlreadyExists, AlreadyExists("error").Code())
 		assert.Equal(t, CodeWrongInput, WrongInput("error").Code())
 		assert.Equal(t, CodeForbidden, Forbidden("error").Code())
+		assert.Equal(t, CodeMethodNotAllowed, MethodNotAllowed("error").Code())
+		assert.Equal(t, CodeUnavailable, Unavailable("error").Code())
+		assert.Equal(t, CodeUnauthorized, Unauthorized("error").Code())
+		assert.Equal(t, CodeHeaderTooLarge, HeaderTooLarge("error").Code())
+		assert.Equal(t, CodePayloadTooLarge, PayloadTooLarge("error").Code())
+		assert.Equal(t, CodeBadGateway, BadGateway("error").Code())
+		assert.Equal(t, CodeGatewayTimeout, GatewayTimeout("error").Code())
 	})
 
 	t.Run("should create error with simple message", func(t *testing.T) {
@@ -31,4 +38,13 @@ func TestAppError(t *testing.T) {
 		assert.Equal(t, "code: 1, error: bug", WrongInput("code: %d, error: %s", 1, "bug").Error())
 		assert.Equal(t, "code: 1, error: bug", Forbidden("code: %d, error: %s", 1, "bug").Error())
 	})
+
+	t.Run("should create error with error code", func(t *testing.T) {
+		err := Forbidden("error").WithErrorCode("FORBIDDEN_NO_SUBJECT")
+
+		assert.Equal(t, CodeForbidden, err.Code())
+		assert.Equal(t, "FORBIDDEN_NO_SUBJECT", err.ErrorCode())
+		assert.Equal(t, "error", err.Error())
+		assert.Empty(t, Forbidden("error").ErrorCode())
+	})
 }
@@ -11,6 +11,13 @@ import (
 	"strings"
 )
 
+// ProxyDisabledAnnotation set to "true" on the Application disables proxying its requests
+const ProxyDisabledAnnotation = "central-application-connectivity-validator.kyma-project.io/proxy-disabled"
+
+// AllowedDestinationsAnnotation on the Application holds the comma-separated destinations to which its requests may be proxied,
+// without the annotation all destinations are allowed
+const AllowedDestinationsAnnotation = "central-application-connectivity-validator.kyma-project.io/allowed-destinations"
+
 type CacheSync interface {
 	Sync(ctx context.Context, applicationName string) error
 	Init(ctx context.Context)
@@ -25,6 +32,17 @@ type cacheSync struct {
 	eventingPathPrefixV2     string
 	eventingPathPrefixEvents string
 	appNamePlaceholder       string
+	clientIDFallback         bool
+}
+
+type CacheSyncOption func(*cacheSync)
+
+// WithClientIDFallback tells that the client IDs of the applications without them are fetched from a fallback source,
+// so that the warnings about such applications describe the validation correctly
+func WithClientIDFallback() func(*cacheSync) {
+	return func(c *cacheSync) {
+		c.clientIDFallback = true
+	}
 }
 
 type CachedAppData struct {
@@ -32,6 +50,9 @@ type CachedAppData struct {
 	AppPathPrefixV1     string
 	AppPathPrefixV2     string
 	AppPathPrefixEvents string
+	ProxyDisabled       bool
+	// AllowedDestinations is nil when all destinations are allowed
+	AllowedDestinations []string
 }
 
 func NewCacheSync(
@@ -42,8 +63,9 @@ func NewCacheSync(
 	appNamePlaceholder,
 	eventingPathPrefixV1,
 	eventingPathPrefixV2,
-	eventingPathPrefixEvents string) CacheSync {
-	return &cacheSync{
+	eventingPathPrefixEvents string,
+	ops ...CacheSyncOption) CacheSync {
+	out := &cacheSync{
 		client:                   client,
 		appCache:                 appCache,
 		log:                      log,
@@ -53,6 +75,12 @@ func NewCacheSync(
 		eventingPathPrefixV2:     eventingPathPrefixV2,
 		eventingPathPrefixEvents: eventingPathPrefixEvents,
 	}
+
+	for _, f := range ops {
+		f(out)
+	}
+
+	return out
 }
 
 func (c *cacheSync) Init(ctx context.Context) {
@@ -123,9 +151,30 @@ func (c *cacheSync) getAppDataFromResource(application *v1alpha1.Application) Ca
 	appData.AppPathPrefixV1 = c.getApplicationPrefix(c.eventingPathPrefixV1, application.Name)
 	appData.AppPathPrefixV2 = c.getApplicationPrefix(c.eventingPathPrefixV2, application.Name)
 	appData.AppPathPrefixEvents = c.getApplicationPrefix(c.eventingPathPrefixEvents, application.Name)
+	appData.ProxyDisabled = application.Annotations[ProxyDisabledAnnotation] == "true"
+	if destinations, found := application.Annotations[AllowedDestinationsAnnotation]; found {
+		appData.AllowedDestinations = []string{}
+		for _, destination := range strings.Split(destinations, ",") {
+			if destination = strings.TrimSpace(destination); destination != "" {
+				appData.AllowedDestinations = append(appData.AllowedDestinations, destination)
+			}
+		}
+	}
 
 	if application.Spec.CompassMetadata != nil {
 		appData.ClientIDs = append(appData.ClientIDs, application.Spec.CompassMetadata.Authentication.ClientIds...)
+
+		if len(appData.ClientIDs) == 0 && c.clientIDFallback {
+			c.log.WithContext().
+				With("controller", c.controllerName).
+				With("name", application.Name).
+				Warnf("Application has Compass metadata without authentication client IDs, the client IDs are fetched from the fallback source for validation.")
+		} else if len(appData.ClientIDs) == 0 {
+			c.log.WithContext().
+				With("controller", c.controllerName).
+				With("name", application.Name).
+				Warnf("Application has Compass metadata without authentication client IDs, the application name is used for validation.")
+		}
 	}
 
 	return appData
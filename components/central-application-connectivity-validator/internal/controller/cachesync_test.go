@@ -11,6 +11,8 @@ import (
 	applicationconnectorv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/client/clientset/versioned/typed/applicationconnector/v1alpha1"
 	"github.com/patrickmn/go-cache"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -32,6 +34,22 @@ var (
 		AppPathPrefixEvents: "/my-app/events",
 	}
 
+	appDataProxyDisabled = CachedAppData{
+		ClientIDs:           []string{},
+		AppPathPrefixV1:     "/my-app/v1/events",
+		AppPathPrefixV2:     "/my-app/v2/events",
+		AppPathPrefixEvents: "/my-app/events",
+		ProxyDisabled:       true,
+	}
+
+	appDataAllowedDestinations = CachedAppData{
+		ClientIDs:           []string{},
+		AppPathPrefixV1:     "/my-app/v1/events",
+		AppPathPrefixV2:     "/my-app/v2/events",
+		AppPathPrefixEvents: "/my-app/events",
+		AllowedDestinations: []string{"cloud-events", "legacy-events"},
+	}
+
 	appData1Client = CachedAppData{
 		ClientIDs:           []string{"client-1"},
 		AppPathPrefixV1:     "/my-app/v1/events",
@@ -119,6 +137,59 @@ func TestCacheSync(t *testing.T) {
 				require.Equal(t, appData2Clients, v)
 			},
 		},
+		{
+			name: "Add new application to cache with compass metadata and empty authentication",
+			setup: func(t *testing.T, applicationName string, fc *fakeClient, appCache *cache.Cache) {
+				require.NoError(t, fc.Create(&v1alpha1.Application{
+					ObjectMeta: v1.ObjectMeta{
+						Name: applicationName,
+					},
+					Spec: v1alpha1.ApplicationSpec{
+						CompassMetadata: &v1alpha1.CompassMetadata{
+							ApplicationID:  "compass-app-id",
+							Authentication: v1alpha1.Authentication{},
+						},
+					},
+				}))
+			},
+			check: func(t *testing.T, applicationName string, appCache *cache.Cache) {
+				v, found := appCache.Get(applicationName)
+				require.True(t, found)
+				require.Equal(t, appDataNoClients, v)
+			},
+		},
+		{
+			name: "Add new application to cache with disabled proxying",
+			setup: func(t *testing.T, applicationName string, fc *fakeClient, appCache *cache.Cache) {
+				require.NoError(t, fc.Create(&v1alpha1.Application{
+					ObjectMeta: v1.ObjectMeta{
+						Name:        applicationName,
+						Annotations: map[string]string{ProxyDisabledAnnotation: "true"},
+					},
+				}))
+			},
+			check: func(t *testing.T, applicationName string, appCache *cache.Cache) {
+				v, found := appCache.Get(applicationName)
+				require.True(t, found)
+				require.Equal(t, appDataProxyDisabled, v)
+			},
+		},
+		{
+			name: "Add new application to cache with allowed destinations",
+			setup: func(t *testing.T, applicationName string, fc *fakeClient, appCache *cache.Cache) {
+				require.NoError(t, fc.Create(&v1alpha1.Application{
+					ObjectMeta: v1.ObjectMeta{
+						Name:        applicationName,
+						Annotations: map[string]string{AllowedDestinationsAnnotation: "cloud-events, legacy-events,"},
+					},
+				}))
+			},
+			check: func(t *testing.T, applicationName string, appCache *cache.Cache) {
+				v, found := appCache.Get(applicationName)
+				require.True(t, found)
+				require.Equal(t, appDataAllowedDestinations, v)
+			},
+		},
 		{
 			name: "Delete application from cache",
 			setup: func(t *testing.T, applicationName string, fc *fakeClient, appCache *cache.Cache) {
@@ -157,6 +228,58 @@ func TestCacheSync(t *testing.T) {
 	}
 }
 
+func TestCacheSync_CompassMetadataWithoutClientIDs(t *testing.T) {
+	const name = "my-app"
+
+	testCases := []struct {
+		name            string
+		ops             []CacheSyncOption
+		expectedWarning string
+	}{
+		{
+			name:            "should warn that the application name is used without client ID fallback",
+			expectedWarning: "Application has Compass metadata without authentication client IDs, the application name is used for validation.",
+		},
+		{
+			name:            "should warn that the client IDs are fetched with client ID fallback",
+			ops:             []CacheSyncOption{WithClientIDFallback()},
+			expectedWarning: "Application has Compass metadata without authentication client IDs, the client IDs are fetched from the fallback source for validation.",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			core, observedLogs := observer.New(zap.WarnLevel)
+			log, err := logger.New(logger.TEXT, logger.DEBUG, core)
+			require.NoError(t, err)
+			appCache := cache.New(60*time.Second, 60*time.Second)
+			fc := NewFakeClient()
+			require.NoError(t, fc.Create(&v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{
+					Name: name,
+				},
+				Spec: v1alpha1.ApplicationSpec{
+					CompassMetadata: &v1alpha1.CompassMetadata{
+						ApplicationID: "compass-app-id",
+					},
+				},
+			}))
+
+			cacheSync := NewCacheSync(log, fc, appCache, "test-controller", "%%APP_NAME%%", "/%%APP_NAME%%/v1/events", "/%%APP_NAME%%/v2/events", "/%%APP_NAME%%/events", tc.ops...)
+
+			// when
+			err = cacheSync.Sync(context.Background(), name)
+
+			// then
+			require.NoError(t, err)
+			warnings := observedLogs.FilterLevelExact(zap.WarnLevel).All()
+			require.Len(t, warnings, 1)
+			require.Equal(t, tc.expectedWarning, warnings[0].Message)
+		})
+	}
+}
+
 func TestCacheInit(t *testing.T) {
 	const name = "my-app"
 	type setup func(t *testing.T, applicationName string, fc *fakeClient, cache *cache.Cache)
@@ -8,6 +8,7 @@ import (
 	gocache "github.com/patrickmn/go-cache"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -17,7 +18,26 @@ type Controller interface {
 }
 
 type controller struct {
-	cacheSync CacheSync
+	cacheSync               CacheSync
+	cacheSyncOptions        []CacheSyncOption
+	maxConcurrentReconciles int
+}
+
+type Option func(*controller)
+
+// WithMaxConcurrentReconciles sets the number of Applications reconciled in parallel, 1 by default.
+// The same Application is never reconciled concurrently, and the cache is safe for concurrent use.
+func WithMaxConcurrentReconciles(n int) func(*controller) {
+	return func(c *controller) {
+		c.maxConcurrentReconciles = n
+	}
+}
+
+// WithCacheSyncOptions sets the options of the cache sync updating the cache from the Applications
+func WithCacheSyncOptions(ops ...CacheSyncOption) func(*controller) {
+	return func(c *controller) {
+		c.cacheSyncOptions = append(c.cacheSyncOptions, ops...)
+	}
 }
 
 func NewController(
@@ -27,10 +47,19 @@ func NewController(
 	appNamePlaceholder,
 	eventingPathPrefixV1,
 	eventingPathPrefixV2,
-	eventingPathPrefixEvents string) Controller {
-	return &controller{
-		cacheSync: NewCacheSync(log, client, appCache, "cache_sync_controller", appNamePlaceholder, eventingPathPrefixV1, eventingPathPrefixV2, eventingPathPrefixEvents),
+	eventingPathPrefixEvents string,
+	ops ...Option) Controller {
+	out := &controller{
+		maxConcurrentReconciles: 1,
 	}
+
+	for _, f := range ops {
+		f(out)
+	}
+
+	out.cacheSync = NewCacheSync(log, client, appCache, "cache_sync_controller", appNamePlaceholder, eventingPathPrefixV1, eventingPathPrefixV2, eventingPathPrefixEvents, out.cacheSyncOptions...)
+
+	return out
 }
 
 func (c *controller) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
@@ -43,5 +72,12 @@ func (c *controller) SetupWithManager(mgr ctrl.Manager) error {
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.Application{}).
+		WithOptions(c.options()).
 		Complete(c)
 }
+
+func (c *controller) options() ctrlcontroller.Options {
+	return ctrlcontroller.Options{
+		MaxConcurrentReconciles: c.maxConcurrentReconciles,
+	}
+}
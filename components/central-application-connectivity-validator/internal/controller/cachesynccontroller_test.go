@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/common/logging/logger"
+	"github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestController_MaxConcurrentReconciles(t *testing.T) {
+	log, err := logger.New(logger.TEXT, logger.ERROR)
+	require.NoError(t, err)
+
+	t.Run("should reconcile one Application at a time by default", func(t *testing.T) {
+		// when
+		c := NewController(log, nil, cache.New(time.Minute, time.Minute), "%%APP_NAME%%", "/%%APP_NAME%%/v1/events", "/%%APP_NAME%%/v2/events", "/%%APP_NAME%%/events")
+
+		// then
+		assert.Equal(t, 1, c.(*controller).options().MaxConcurrentReconciles)
+	})
+
+	t.Run("should pass the max concurrent reconciles to the controller options", func(t *testing.T) {
+		// when
+		c := NewController(log, nil, cache.New(time.Minute, time.Minute), "%%APP_NAME%%", "/%%APP_NAME%%/v1/events", "/%%APP_NAME%%/v2/events", "/%%APP_NAME%%/events",
+			WithMaxConcurrentReconciles(8))
+
+		// then
+		assert.Equal(t, 8, c.(*controller).options().MaxConcurrentReconciles)
+	})
+
+	t.Run("should keep the cache consistent when reconciling Applications concurrently", func(t *testing.T) {
+		// given
+		const appCount = 50
+		const workers = 8
+
+		appCache := cache.New(time.Minute, time.Minute)
+		fc := NewFakeClient()
+		for i := 0; i < appCount; i++ {
+			require.NoError(t, fc.Create(&v1alpha1.Application{
+				ObjectMeta: v1.ObjectMeta{Name: fmt.Sprintf("app-%d", i)},
+				Spec: v1alpha1.ApplicationSpec{
+					CompassMetadata: &v1alpha1.CompassMetadata{
+						Authentication: v1alpha1.Authentication{ClientIds: []string{fmt.Sprintf("client-%d", i)}},
+					},
+				},
+			}))
+		}
+		c := &controller{
+			cacheSync:               NewCacheSync(log, fc, appCache, "test-controller", "%%APP_NAME%%", "/%%APP_NAME%%/v1/events", "/%%APP_NAME%%/v2/events", "/%%APP_NAME%%/events"),
+			maxConcurrentReconciles: workers,
+		}
+
+		requests := make(chan reconcile.Request)
+		var wg sync.WaitGroup
+		for w := 0; w < c.maxConcurrentReconciles; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for request := range requests {
+					_, err := c.Reconcile(context.Background(), request)
+					assert.NoError(t, err)
+				}
+			}()
+		}
+
+		// when
+		for i := 0; i < appCount; i++ {
+			requests <- reconcile.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("app-%d", i)}}
+		}
+		close(requests)
+		wg.Wait()
+
+		// then
+		for i := 0; i < appCount; i++ {
+			v, found := appCache.Get(fmt.Sprintf("app-%d", i))
+			require.True(t, found)
+			assert.Equal(t, []string{fmt.Sprintf("client-%d", i)}, v.(CachedAppData).ClientIDs)
+		}
+	})
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"context"
+	"time"
+)
+
+// WarmCache syncs the applications to the cache, retrying the failed ones after retryInterval,
+// until all of them are synced or the context is done. It returns the context error when some applications were not synced.
+func WarmCache(ctx context.Context, cacheSync CacheSync, applicationNames []string, retryInterval time.Duration) error {
+	pending := applicationNames
+	for {
+		var failed []string
+		for _, name := range pending {
+			if err := cacheSync.Sync(ctx, name); err != nil {
+				failed = append(failed, name)
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		pending = failed
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyCacheSync fails the first failures syncs of each application, then caches it
+type flakyCacheSync struct {
+	cache    *cache.Cache
+	failures int
+	syncs    map[string]int
+}
+
+func (s *flakyCacheSync) Sync(_ context.Context, applicationName string) error {
+	s.syncs[applicationName]++
+	if s.syncs[applicationName] <= s.failures {
+		return errors.New("apiserver unavailable")
+	}
+	s.cache.Set(applicationName, appDataNoClients, cache.NoExpiration)
+	return nil
+}
+
+func (s *flakyCacheSync) Init(_ context.Context) {}
+
+func TestWarmCache(t *testing.T) {
+	t.Run("should populate the cache with the applications", func(t *testing.T) {
+		// given
+		appCache := cache.New(cache.NoExpiration, cache.NoExpiration)
+		cacheSync := &flakyCacheSync{cache: appCache, syncs: map[string]int{}}
+
+		// when
+		err := WarmCache(context.Background(), cacheSync, []string{"app-1", "app-2"}, time.Millisecond)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2, appCache.ItemCount())
+	})
+
+	t.Run("should retry the failed applications only", func(t *testing.T) {
+		// given
+		appCache := cache.New(cache.NoExpiration, cache.NoExpiration)
+		cacheSync := &flakyCacheSync{cache: appCache, failures: 2, syncs: map[string]int{}}
+
+		// when
+		err := WarmCache(context.Background(), cacheSync, []string{"app-1"}, time.Millisecond)
+
+		// then
+		require.NoError(t, err)
+		_, found := appCache.Get("app-1")
+		assert.True(t, found)
+		assert.Equal(t, 3, cacheSync.syncs["app-1"])
+	})
+
+	t.Run("should give up when the context is done", func(t *testing.T) {
+		// given
+		appCache := cache.New(cache.NoExpiration, cache.NoExpiration)
+		cacheSync := &flakyCacheSync{cache: appCache, failures: 1000, syncs: map[string]int{}}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		// when
+		err := WarmCache(ctx, cacheSync, []string{"app-1"}, time.Millisecond)
+
+		// then
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Zero(t, appCache.ItemCount())
+	})
+}
@@ -129,7 +129,7 @@ var _ = BeforeSuite(func() {
 		defer GinkgoRecover()
 
 		srv := http.Server{
-			Handler: validationproxy.NewHandler(tracingMiddleware),
+			Handler: validationproxy.NewHandler(tracingMiddleware, ""),
 			Addr:    fmt.Sprintf(":%s", testProxyServerPort),
 		}
 
@@ -6,11 +6,21 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewHandler() http.Handler {
+// NewHandler creates the external API handler, ready reports whether the validator is ready to proxy the requests.
+// The decisions handler dumping the recent proxy decisions and the applications handler dumping the request counts
+// per application are served only when they are not nil.
+func NewHandler(ready func() bool, decisions, applications http.Handler) http.Handler {
 
 	router := mux.NewRouter()
 
 	router.Path("/v1/health").Handler(NewHealthCheckHandler())
+	router.Path("/v1/ready").Handler(NewReadinessHandler(ready))
+	if decisions != nil {
+		router.Path("/v1/decisions").Methods(http.MethodGet).Handler(decisions)
+	}
+	if applications != nil {
+		router.Path("/v1/applications").Methods(http.MethodGet).Handler(applications)
+	}
 
 	return router
 }
@@ -0,0 +1,76 @@
+package externalapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Decisions(t *testing.T) {
+	ready := func() bool { return true }
+	decisions := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	t.Run("should serve decisions when handler is set", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/decisions", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		NewHandler(ready, decisions, nil).ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("should respond with 404 status code when handler is not set", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/decisions", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		NewHandler(ready, nil, nil).ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func TestHandler_Applications(t *testing.T) {
+	ready := func() bool { return true }
+	applications := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	t.Run("should serve applications when handler is set", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/applications", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		NewHandler(ready, nil, applications).ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusTeapot, rr.Code)
+	})
+
+	t.Run("should respond with 404 status code when handler is not set", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/applications", nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+
+		// when
+		NewHandler(ready, nil, nil).ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,16 @@
+package externalapi
+
+import (
+	"net/http"
+)
+
+// NewReadinessHandler creates handler responding with 200 once ready reports true, and with 503 before
+func NewReadinessHandler(ready func() bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if !ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,33 @@
+package externalapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadinessHandler_HandleRequest(t *testing.T) {
+	t.Run("should respond with 503 status code until ready", func(t *testing.T) {
+		// given
+		var ready atomic.Bool
+		handler := NewHandler(ready.Load, nil, nil)
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/ready", nil)
+		require.NoError(t, err)
+
+		// when
+		notReady := httptest.NewRecorder()
+		handler.ServeHTTP(notReady, req)
+		ready.Store(true)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, notReady.Code)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	})
+}
@@ -6,4 +6,5 @@ const (
 
 const (
 	ContentTypeApplicationJson = "application/json;charset=UTF-8"
+	ContentTypeTextPlain       = "text/plain;charset=UTF-8"
 )
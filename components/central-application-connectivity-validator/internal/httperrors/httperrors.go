@@ -7,8 +7,9 @@ import (
 )
 
 type ErrorResponse struct {
-	Code  int    `json:"code"`
-	Error string `json:"error"`
+	Code      int    `json:"code"`
+	ErrorCode string `json:"errorCode,omitempty"`
+	Error     string `json:"error"`
 }
 
 func errorCodeToHttpStatus(code int) int {
@@ -25,6 +26,20 @@ func errorCodeToHttpStatus(code int) int {
 		return http.StatusForbidden
 	case apperrors.CodeBadRequest:
 		return http.StatusBadRequest
+	case apperrors.CodeMethodNotAllowed:
+		return http.StatusMethodNotAllowed
+	case apperrors.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case apperrors.CodeUnauthorized:
+		return http.StatusUnauthorized
+	case apperrors.CodeHeaderTooLarge:
+		return http.StatusRequestHeaderFieldsTooLarge
+	case apperrors.CodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case apperrors.CodeBadGateway:
+		return http.StatusBadGateway
+	case apperrors.CodeGatewayTimeout:
+		return http.StatusGatewayTimeout
 	default:
 		return http.StatusInternalServerError
 	}
@@ -32,5 +47,5 @@ func errorCodeToHttpStatus(code int) int {
 
 func AppErrorToResponse(appError apperrors.AppError) (status int, body ErrorResponse) {
 	httpCode := errorCodeToHttpStatus(appError.Code())
-	return httpCode, ErrorResponse{httpCode, appError.Error()}
+	return httpCode, ErrorResponse{httpCode, appError.ErrorCode(), appError.Error()}
 }
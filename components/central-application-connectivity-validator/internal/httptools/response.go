@@ -2,7 +2,11 @@ package httptools
 
 import (
 	"encoding/json"
+	"fmt"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -21,6 +25,55 @@ func RespondWithError(log *zap.SugaredLogger, w http.ResponseWriter, apperr appe
 	json.NewEncoder(w).Encode(responseBody)
 }
 
+// RespondWithNegotiatedError responds like RespondWithError, but writes the error as plain text
+// when the Accept header of the request prefers text/plain to JSON
+func RespondWithNegotiatedError(log *zap.SugaredLogger, w http.ResponseWriter, r *http.Request, apperr apperrors.AppError) {
+	if !prefersPlainText(r.Header.Get("Accept")) {
+		RespondWithError(log, w, apperr)
+		return
+	}
+
+	log.Errorf("Error: %s", apperr.Error())
+
+	statusCode, responseBody := httperrors.AppErrorToResponse(apperr)
+
+	w.Header().Set(httpconsts.HeaderContentType, httpconsts.ContentTypeTextPlain)
+	w.WriteHeader(statusCode)
+	if responseBody.ErrorCode != "" {
+		fmt.Fprintf(w, "%s: ", responseBody.ErrorCode)
+	}
+	fmt.Fprintln(w, responseBody.Error)
+}
+
+// prefersPlainText reports whether text/plain has higher quality than application/json in the Accept header,
+// JSON wins the ties
+func prefersPlainText(accept string) bool {
+	var jsonQuality, textQuality float64
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case "application/json", "application/*":
+			jsonQuality = max(jsonQuality, quality)
+		case "text/plain", "text/*":
+			textQuality = max(textQuality, quality)
+		case "*/*":
+			jsonQuality = max(jsonQuality, quality)
+			textQuality = max(textQuality, quality)
+		}
+	}
+	return textQuality > jsonQuality
+}
+
 func Respond(w http.ResponseWriter, statusCode int) {
 	w.Header().Set(httpconsts.HeaderContentType, httpconsts.ContentTypeApplicationJson)
 	w.WriteHeader(statusCode)
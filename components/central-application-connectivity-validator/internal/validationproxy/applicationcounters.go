@@ -0,0 +1,101 @@
+package validationproxy
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httptools"
+)
+
+// ApplicationCount summarizes the requests of a single application
+type ApplicationCount struct {
+	Requests uint64    `json:"requests"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// DefaultMaxApplicationCounters is the default maximum number of applications counted at the same time
+const DefaultMaxApplicationCounters = 10000
+
+// ApplicationCounters counts the requests per application. The applications without requests for the idle timeout
+// are evicted, and at most maxApplications are counted at the same time, so that the number of counters is bounded
+// even when the requests name many applications.
+type ApplicationCounters struct {
+	idleTimeout     time.Duration
+	maxApplications int
+	now             func() time.Time
+
+	mu           sync.Mutex
+	counts       map[string]*ApplicationCount
+	lastEviction time.Time
+}
+
+// NewApplicationCounters creates ApplicationCounters evicting the applications idle for idleTimeout and counting
+// at most maxApplications, a non-positive maxApplications uses DefaultMaxApplicationCounters
+func NewApplicationCounters(idleTimeout time.Duration, maxApplications int) *ApplicationCounters {
+	if maxApplications <= 0 {
+		maxApplications = DefaultMaxApplicationCounters
+	}
+	return &ApplicationCounters{
+		idleTimeout:     idleTimeout,
+		maxApplications: maxApplications,
+		now:             time.Now,
+		counts:          map[string]*ApplicationCount{},
+	}
+}
+
+// record counts the request of the application, the nil counters count nothing. New applications are not counted
+// while maxApplications are counted, until the idle ones are evicted.
+func (c *ApplicationCounters) record(applicationName string) {
+	if c == nil || applicationName == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	// the idle applications are looked for at most once per idle timeout, so that requests do not scan all counters
+	if now.Sub(c.lastEviction) >= c.idleTimeout {
+		c.evictIdle(now)
+		c.lastEviction = now
+	}
+
+	count, found := c.counts[applicationName]
+	if !found {
+		if len(c.counts) >= c.maxApplications {
+			return
+		}
+		count = &ApplicationCount{}
+		c.counts[applicationName] = count
+	}
+	count.Requests++
+	count.LastSeen = now
+}
+
+func (c *ApplicationCounters) evictIdle(now time.Time) {
+	for applicationName, count := range c.counts {
+		if now.Sub(count.LastSeen) >= c.idleTimeout {
+			delete(c.counts, applicationName)
+		}
+	}
+}
+
+// Counts returns the counts of the applications which are not idle
+func (c *ApplicationCounters) Counts() map[string]ApplicationCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.evictIdle(c.now())
+
+	result := make(map[string]ApplicationCount, len(c.counts))
+	for applicationName, count := range c.counts {
+		result[applicationName] = *count
+	}
+	return result
+}
+
+// ServeHTTP responds with the counts of the applications which are not idle as JSON
+func (c *ApplicationCounters) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	httptools.RespondWithBody(w, http.StatusOK, c.Counts())
+}
@@ -0,0 +1,169 @@
+package validationproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appconnv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestApplicationCounters(idleTimeout time.Duration) (*ApplicationCounters, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	counters := NewApplicationCounters(idleTimeout, 3)
+	counters.now = clock.Now
+	return counters, clock
+}
+
+func TestApplicationCounters(t *testing.T) {
+	t.Run("should count requests per application", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+
+		// when
+		counters.record("app-1")
+		clock.now = clock.now.Add(time.Second)
+		counters.record("app-1")
+		counters.record("app-2")
+		counters.record("")
+
+		// then
+		assert.Equal(t, map[string]ApplicationCount{
+			"app-1": {Requests: 2, LastSeen: clock.now},
+			"app-2": {Requests: 1, LastSeen: clock.now},
+		}, counters.Counts())
+	})
+
+	t.Run("should evict idle applications", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+		counters.record("app-1")
+		counters.record("app-2")
+
+		// when
+		clock.now = clock.now.Add(30 * time.Second)
+		counters.record("app-2")
+		clock.now = clock.now.Add(30 * time.Second)
+
+		// then
+		assert.Equal(t, map[string]ApplicationCount{
+			"app-2": {Requests: 2, LastSeen: clock.now.Add(-30 * time.Second)},
+		}, counters.Counts())
+	})
+
+	t.Run("should evict idle applications while recording", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+		for i := 0; i < 3; i++ {
+			counters.record(fmt.Sprintf("app-%d", i))
+		}
+
+		// when
+		clock.now = clock.now.Add(time.Minute)
+		counters.record("app-new")
+
+		// then
+		assert.Len(t, counters.counts, 1)
+		assert.Contains(t, counters.counts, "app-new")
+	})
+
+	t.Run("should count again application after eviction", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+		counters.record("app-1")
+		counters.record("app-1")
+
+		// when
+		clock.now = clock.now.Add(2 * time.Minute)
+		counters.record("app-1")
+
+		// then
+		assert.Equal(t, map[string]ApplicationCount{
+			"app-1": {Requests: 1, LastSeen: clock.now},
+		}, counters.Counts())
+	})
+
+	t.Run("should not count new applications above the maximum", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+		for i := 0; i < 3; i++ {
+			counters.record(fmt.Sprintf("app-%d", i))
+		}
+
+		// when
+		counters.record("app-new")
+		counters.record("app-0")
+
+		// then
+		assert.Len(t, counters.counts, 3)
+		assert.NotContains(t, counters.counts, "app-new")
+		assert.Equal(t, ApplicationCount{Requests: 2, LastSeen: clock.now}, *counters.counts["app-0"])
+	})
+
+	t.Run("should count new application after idle ones are evicted at the maximum", func(t *testing.T) {
+		// given
+		counters, clock := newTestApplicationCounters(time.Minute)
+		for i := 0; i < 3; i++ {
+			counters.record(fmt.Sprintf("app-%d", i))
+		}
+
+		// when
+		clock.now = clock.now.Add(time.Minute)
+		counters.record("app-new")
+
+		// then
+		assert.Equal(t, map[string]ApplicationCount{
+			"app-new": {Requests: 1, LastSeen: clock.now},
+		}, counters.Counts())
+	})
+
+	t.Run("should dump counts as JSON", func(t *testing.T) {
+		// given
+		counters, _ := newTestApplicationCounters(time.Minute)
+		counters.record("app-1")
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/applications", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+
+		// when
+		counters.ServeHTTP(recorder, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"app-1":{"requests":1,"lastSeen":"2023-05-01T12:00:00Z"}}`, recorder.Body.String())
+	})
+}
+
+func TestProxyHandler_ApplicationCounters(t *testing.T) {
+	t.Run("should count only requests of application with approved identity", func(t *testing.T) {
+		// given
+		counters := NewApplicationCounters(time.Minute, 0)
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithApplicationCounters(counters))
+
+		// when
+		for _, commonName := range []string{applicationName, "other-application"} {
+			h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, commonName))
+		}
+		h.do(http.MethodPost, "/unknown-application/events", fmt.Sprintf(harnessCertInfoHeaderValue, "unknown-application"))
+		h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), "")
+
+		// then
+		counts := counters.Counts()
+		require.Len(t, counts, 1)
+		require.Contains(t, counts, applicationName)
+		assert.Equal(t, uint64(1), counts[applicationName].Requests)
+	})
+}
@@ -0,0 +1,126 @@
+package validationproxy
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker stops proxying to a failing destination. It opens after failureThreshold consecutive failures,
+// rejects the requests for openTimeout, and then lets a single probe request through to check the recovery.
+// A probe which has not ended after openTimeout is given up, and the next request probes again.
+// The nil circuitBreaker never opens.
+type circuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+	now              func() time.Time
+
+	mu                  sync.Mutex
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	probeStartedAt      time.Time
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+	}
+}
+
+// allow reports whether the request can be proxied, otherwise it returns the time left until the next probe
+func (cb *circuitBreaker) allow() (bool, time.Duration) {
+	if cb == nil {
+		return true, 0
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if elapsed := cb.now().Sub(cb.openedAt); elapsed < cb.openTimeout {
+			return false, cb.openTimeout - elapsed
+		}
+		cb.state = circuitHalfOpen
+		cb.probeStartedAt = cb.now()
+		return true, 0
+	case circuitHalfOpen:
+		if elapsed := cb.now().Sub(cb.probeStartedAt); elapsed < cb.openTimeout {
+			// the probe request is still in progress
+			return false, cb.openTimeout - elapsed
+		}
+		cb.probeStartedAt = cb.now()
+		return true, 0
+	}
+	return true, 0
+}
+
+// record updates the state of the breaker with the result of the proxied request
+func (cb *circuitBreaker) record(failed bool) {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !failed {
+		cb.state = circuitClosed
+		cb.consecutiveFailures = 0
+		return
+	}
+
+	cb.consecutiveFailures++
+	if cb.state == circuitHalfOpen || cb.consecutiveFailures >= cb.failureThreshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// abandon lets the next request probe the destination when the probe request ended without a response,
+// for example because the client cancelled it
+func (cb *circuitBreaker) abandon() {
+	if cb == nil {
+		return
+	}
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == circuitHalfOpen {
+		cb.state = circuitOpen
+	}
+}
+
+// statusRecorder captures the status code written by the proxy
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController, so that the proxy can still flush the responses
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,40 @@
+package validationproxy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+)
+
+// ErrApplicationNotFound is returned by ClientIDResolver for the applications it does not know
+var ErrApplicationNotFound = errors.New("application not found")
+
+// ClientIDResolver resolves the client IDs allowed to send requests on behalf of the application.
+// An empty list means the application has no client IDs and its name is matched with the certificate subject.
+type ClientIDResolver interface {
+	ResolveClientIDs(ctx context.Context, applicationName string) ([]string, error)
+}
+
+type cacheClientIDResolver struct {
+	cache Cache
+}
+
+// NewCacheClientIDResolver creates ClientIDResolver reading the client IDs of the Application resources from the cache
+// filled by the controller
+func NewCacheClientIDResolver(cache Cache) ClientIDResolver {
+	return &cacheClientIDResolver{
+		cache: cache,
+	}
+}
+
+func (r *cacheClientIDResolver) ResolveClientIDs(_ context.Context, applicationName string) ([]string, error) {
+	appData, found := r.cache.Get(applicationName)
+	if !found {
+		clientIDCacheMisses.Inc()
+		return nil, ErrApplicationNotFound
+	}
+	clientIDCacheHits.Inc()
+
+	return appData.(controller.CachedAppData).ClientIDs, nil
+}
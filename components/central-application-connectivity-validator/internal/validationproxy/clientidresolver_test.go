@@ -0,0 +1,35 @@
+package validationproxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheClientIDResolver(t *testing.T) {
+	idCache := cache.New(time.Minute, time.Minute)
+	idCache.Set(applicationName, controller.CachedAppData{ClientIDs: []string{"client-1"}}, cache.NoExpiration)
+	resolver := NewCacheClientIDResolver(idCache)
+
+	t.Run("should return client IDs of the cached application", func(t *testing.T) {
+		// when
+		clientIDs, err := resolver.ResolveClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-1"}, clientIDs)
+	})
+
+	t.Run("should return ErrApplicationNotFound for application missing in the cache", func(t *testing.T) {
+		// when
+		_, err := resolver.ResolveClientIDs(context.Background(), "unknown-application")
+
+		// then
+		assert.ErrorIs(t, err, ErrApplicationNotFound)
+	})
+}
@@ -0,0 +1,91 @@
+package validationproxy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClientIDSource provides client IDs for applications that are not managed by Compass
+type ClientIDSource interface {
+	GetClientIDs(ctx context.Context, applicationName string) ([]string, error)
+}
+
+type configMapClientIDSource struct {
+	client    client.Reader
+	namespace string
+	name      string
+
+	attempts int
+	backoff  time.Duration
+}
+
+type ClientIDSourceOption func(*configMapClientIDSource)
+
+// WithFetchRetries retries reading the ConfigMap after the transient API server errors, such as timeouts and 5xx responses,
+// up to the number of attempts. The backoff doubles after every attempt.
+func WithFetchRetries(attempts int, backoff time.Duration) func(*configMapClientIDSource) {
+	return func(s *configMapClientIDSource) {
+		s.attempts = attempts
+		s.backoff = backoff
+	}
+}
+
+// NewConfigMapClientIDSource creates ClientIDSource reading client IDs from the ConfigMap entry named after the application.
+// The entry value holds comma-separated client IDs.
+func NewConfigMapClientIDSource(client client.Reader, namespace, name string, ops ...ClientIDSourceOption) ClientIDSource {
+	s := &configMapClientIDSource{
+		client:    client,
+		namespace: namespace,
+		name:      name,
+		attempts:  1,
+	}
+	for _, o := range ops {
+		o(s)
+	}
+	return s
+}
+
+func (s *configMapClientIDSource) GetClientIDs(ctx context.Context, applicationName string) ([]string, error) {
+	var configMap corev1.ConfigMap
+	if err := s.getConfigMap(ctx, &configMap); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	var clientIDs []string
+	for _, id := range strings.Split(configMap.Data[applicationName], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			clientIDs = append(clientIDs, id)
+		}
+	}
+
+	return clientIDs, nil
+}
+
+func (s *configMapClientIDSource) getConfigMap(ctx context.Context, configMap *corev1.ConfigMap) error {
+	backoff := s.backoff
+	for attempt := 1; ; attempt++ {
+		err := s.client.Get(ctx, types.NamespacedName{Namespace: s.namespace, Name: s.name}, configMap)
+		if err == nil || attempt >= s.attempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isRetryable reports whether the API server error is transient
+func isRetryable(err error) bool {
+	return k8serrors.IsTimeout(err) || k8serrors.IsServerTimeout(err) || k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsInternalError(err) || k8serrors.IsServiceUnavailable(err) || k8serrors.IsUnexpectedServerError(err)
+}
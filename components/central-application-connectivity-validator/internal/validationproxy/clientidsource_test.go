@@ -0,0 +1,294 @@
+package validationproxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapClientIDSource(t *testing.T) {
+	const (
+		namespace = "kyma-system"
+		name      = "client-ids"
+	)
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+		},
+		Data: map[string]string{
+			applicationName: "client-1, client-2,,",
+		},
+	}
+
+	t.Run("should return client IDs of the application", func(t *testing.T) {
+		// given
+		source := NewConfigMapClientIDSource(fake.NewClientBuilder().WithObjects(configMap).Build(), namespace, name)
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-1", "client-2"}, clientIDs)
+	})
+
+	t.Run("should return no client IDs when application entry is missing", func(t *testing.T) {
+		// given
+		source := NewConfigMapClientIDSource(fake.NewClientBuilder().WithObjects(configMap).Build(), namespace, name)
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), "other-application")
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, clientIDs)
+	})
+
+	t.Run("should return no client IDs when ConfigMap is missing", func(t *testing.T) {
+		// given
+		source := NewConfigMapClientIDSource(fake.NewClientBuilder().Build(), namespace, name)
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, clientIDs)
+	})
+
+	t.Run("should retry transient error", func(t *testing.T) {
+		// given
+		reader := &failingReader{Reader: fake.NewClientBuilder().WithObjects(configMap).Build(), failures: 1,
+			err: k8serrors.NewServiceUnavailable("apiserver overloaded")}
+		source := NewConfigMapClientIDSource(reader, namespace, name, WithFetchRetries(3, time.Millisecond))
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-1", "client-2"}, clientIDs)
+		assert.Equal(t, 2, reader.calls)
+	})
+
+	t.Run("should return transient error after the last attempt", func(t *testing.T) {
+		// given
+		reader := &failingReader{Reader: fake.NewClientBuilder().WithObjects(configMap).Build(), failures: 3,
+			err: k8serrors.NewTimeoutError("request timed out", 1)}
+		source := NewConfigMapClientIDSource(reader, namespace, name, WithFetchRetries(3, time.Millisecond))
+
+		// when
+		_, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, 3, reader.calls)
+	})
+
+	t.Run("should not retry not found error", func(t *testing.T) {
+		// given
+		reader := &failingReader{Reader: fake.NewClientBuilder().Build()}
+		source := NewConfigMapClientIDSource(reader, namespace, name, WithFetchRetries(3, time.Millisecond))
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, clientIDs)
+		assert.Equal(t, 1, reader.calls)
+	})
+
+	t.Run("should not retry by default", func(t *testing.T) {
+		// given
+		reader := &failingReader{Reader: fake.NewClientBuilder().WithObjects(configMap).Build(), failures: 1,
+			err: k8serrors.NewInternalError(assert.AnError)}
+		source := NewConfigMapClientIDSource(reader, namespace, name)
+
+		// when
+		_, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, 1, reader.calls)
+	})
+}
+
+// failingReader returns the error for the first failures calls of Get
+type failingReader struct {
+	client.Reader
+	failures int
+	err      error
+	calls    int
+}
+
+func (r *failingReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	r.calls++
+	if r.calls <= r.failures {
+		return r.err
+	}
+	return r.Reader.Get(ctx, key, obj, opts...)
+}
+
+func TestConfigMapClientIDSource_APIServer(t *testing.T) {
+	const (
+		namespace     = "kyma-system"
+		name          = "client-ids"
+		configMapPath = "/api/v1/namespaces/kyma-system/configmaps/client-ids"
+	)
+
+	configMap := corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Data:       map[string]string{applicationName: "client-1"},
+	}
+
+	t.Run("should get ConfigMap from API server without listing or watching ConfigMaps", func(t *testing.T) {
+		// given
+		apiServer := newFakeAPIServer(t, configMapPath, configMap)
+		source := NewConfigMapClientIDSource(apiServer.reader(t), namespace, name)
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-1"}, clientIDs)
+		assert.Equal(t, []string{http.MethodGet + " " + configMapPath}, apiServer.requests())
+	})
+
+	t.Run("should retry transient API server errors", func(t *testing.T) {
+		// given
+		apiServer := newFakeAPIServer(t, configMapPath, configMap)
+		apiServer.fail(http.StatusServiceUnavailable, metav1.StatusReasonServiceUnavailable, 1)
+		apiServer.fail(http.StatusInternalServerError, metav1.StatusReasonInternalError, 1)
+		source := NewConfigMapClientIDSource(apiServer.reader(t), namespace, name, WithFetchRetries(3, time.Millisecond))
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"client-1"}, clientIDs)
+		assert.Len(t, apiServer.requests(), 3)
+	})
+
+	t.Run("should return API server error after the last attempt", func(t *testing.T) {
+		// given
+		apiServer := newFakeAPIServer(t, configMapPath, configMap)
+		apiServer.fail(http.StatusTooManyRequests, metav1.StatusReasonTooManyRequests, 2)
+		source := NewConfigMapClientIDSource(apiServer.reader(t), namespace, name, WithFetchRetries(2, time.Millisecond))
+
+		// when
+		_, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.Error(t, err)
+		assert.True(t, k8serrors.IsTooManyRequests(err))
+		assert.Len(t, apiServer.requests(), 2)
+	})
+
+	t.Run("should not retry API server not found error", func(t *testing.T) {
+		// given
+		apiServer := newFakeAPIServer(t, configMapPath, configMap)
+		apiServer.fail(http.StatusNotFound, metav1.StatusReasonNotFound, 1)
+		source := NewConfigMapClientIDSource(apiServer.reader(t), namespace, name, WithFetchRetries(3, time.Millisecond))
+
+		// when
+		clientIDs, err := source.GetClientIDs(context.Background(), applicationName)
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, clientIDs)
+		assert.Len(t, apiServer.requests(), 1)
+	})
+}
+
+// fakeAPIServer serves the object on the path, after answering the queued failures with API server errors
+type fakeAPIServer struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	failures []metav1.Status
+	received []string
+}
+
+func newFakeAPIServer(t *testing.T, path string, object interface{}) *fakeAPIServer {
+	s := &fakeAPIServer{}
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.received = append(s.received, r.Method+" "+r.URL.Path+queryString(r))
+		var failure *metav1.Status
+		if len(s.failures) > 0 {
+			failure, s.failures = &s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case failure != nil:
+			w.WriteHeader(int(failure.Code))
+			json.NewEncoder(w).Encode(failure)
+		case r.URL.Path == path:
+			json.NewEncoder(w).Encode(object)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func queryString(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return ""
+	}
+	return "?" + r.URL.RawQuery
+}
+
+func (s *fakeAPIServer) fail(code int, reason metav1.StatusReason, times int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := 0; i < times; i++ {
+		s.failures = append(s.failures, metav1.Status{
+			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+			Status:   metav1.StatusFailure,
+			Code:     int32(code),
+			Reason:   reason,
+			Message:  string(reason),
+		})
+	}
+}
+
+func (s *fakeAPIServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.received...)
+}
+
+// reader creates the client reading directly from the API server, like the API reader of the manager
+func (s *fakeAPIServer) reader(t *testing.T) client.Reader {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	c, err := client.New(&rest.Config{Host: s.server.URL}, client.Options{Scheme: scheme.Scheme, Mapper: mapper})
+	require.NoError(t, err)
+	return c
+}
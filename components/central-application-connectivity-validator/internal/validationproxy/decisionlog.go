@@ -0,0 +1,91 @@
+package validationproxy
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httptools"
+)
+
+// Outcomes of the proxy decisions
+const (
+	DecisionOutcomeProxied  = "proxied"
+	DecisionOutcomeRejected = "rejected"
+)
+
+// ProxyDecision describes how the proxy handled a single request, the path is redacted like in the logs
+type ProxyDecision struct {
+	Timestamp   time.Time   `json:"timestamp"`
+	Application string      `json:"application"`
+	Path        string      `json:"path"`
+	Destination Destination `json:"destination,omitempty"`
+	Outcome     string      `json:"outcome"`
+	Status      int         `json:"status"`
+}
+
+type proxyDecisionKey struct{}
+
+func withProxyDecision(ctx context.Context, decision *ProxyDecision) context.Context {
+	return context.WithValue(ctx, proxyDecisionKey{}, decision)
+}
+
+// proxyDecisionFrom returns the decision recorded for the request, nil when the decisions are not recorded
+func proxyDecisionFrom(ctx context.Context) *ProxyDecision {
+	decision, _ := ctx.Value(proxyDecisionKey{}).(*ProxyDecision)
+	return decision
+}
+
+func (d *ProxyDecision) setDestination(destination Destination) {
+	if d != nil {
+		d.Destination = destination
+	}
+}
+
+func (d *ProxyDecision) setProxied() {
+	if d != nil {
+		d.Outcome = DecisionOutcomeProxied
+	}
+}
+
+// DecisionLog keeps the last proxy decisions in a ring buffer of the fixed size, so that they can be inspected
+// without enabling verbose logs
+type DecisionLog struct {
+	mu        sync.Mutex
+	decisions []ProxyDecision
+	next      int
+	full      bool
+}
+
+// NewDecisionLog creates DecisionLog keeping the last size decisions, at least one
+func NewDecisionLog(size int) *DecisionLog {
+	return &DecisionLog{decisions: make([]ProxyDecision, max(size, 1))}
+}
+
+func (l *DecisionLog) add(decision ProxyDecision) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.decisions[l.next] = decision
+	l.next = (l.next + 1) % len(l.decisions)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// Decisions returns the kept decisions from the oldest to the newest
+func (l *DecisionLog) Decisions() []ProxyDecision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.full {
+		return append([]ProxyDecision{}, l.decisions[:l.next]...)
+	}
+	return append(append([]ProxyDecision{}, l.decisions[l.next:]...), l.decisions[:l.next]...)
+}
+
+// ServeHTTP responds with the kept decisions as JSON, from the oldest to the newest
+func (l *DecisionLog) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	httptools.RespondWithBody(w, http.StatusOK, l.Decisions())
+}
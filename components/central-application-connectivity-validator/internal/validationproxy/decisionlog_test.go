@@ -0,0 +1,140 @@
+package validationproxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appconnv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecisionLog(t *testing.T) {
+	applications := func(decisions []ProxyDecision) []string {
+		var result []string
+		for _, decision := range decisions {
+			result = append(result, decision.Application)
+		}
+		return result
+	}
+
+	t.Run("should keep decisions from the oldest to the newest", func(t *testing.T) {
+		// given
+		decisionLog := NewDecisionLog(3)
+
+		// when
+		decisionLog.add(ProxyDecision{Application: "app-1"})
+		decisionLog.add(ProxyDecision{Application: "app-2"})
+
+		// then
+		assert.Equal(t, []string{"app-1", "app-2"}, applications(decisionLog.Decisions()))
+	})
+
+	t.Run("should overwrite the oldest decisions after wraparound", func(t *testing.T) {
+		// given
+		decisionLog := NewDecisionLog(3)
+
+		// when
+		for i := 1; i <= 5; i++ {
+			decisionLog.add(ProxyDecision{Application: fmt.Sprintf("app-%d", i)})
+		}
+
+		// then
+		assert.Equal(t, []string{"app-3", "app-4", "app-5"}, applications(decisionLog.Decisions()))
+	})
+
+	t.Run("should keep all decisions when the buffer is exactly full", func(t *testing.T) {
+		// given
+		decisionLog := NewDecisionLog(2)
+
+		// when
+		decisionLog.add(ProxyDecision{Application: "app-1"})
+		decisionLog.add(ProxyDecision{Application: "app-2"})
+
+		// then
+		assert.Equal(t, []string{"app-1", "app-2"}, applications(decisionLog.Decisions()))
+	})
+
+	t.Run("should dump decisions as JSON", func(t *testing.T) {
+		// given
+		decisionLog := NewDecisionLog(2)
+		decisionLog.add(ProxyDecision{
+			Timestamp:   time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC),
+			Application: "app-1",
+			Path:        "/app-1/events",
+			Destination: DestinationCloudEvents,
+			Outcome:     DecisionOutcomeProxied,
+			Status:      http.StatusOK,
+		})
+		decisionLog.add(ProxyDecision{
+			Timestamp:   time.Date(2023, 5, 1, 12, 0, 1, 0, time.UTC),
+			Application: "app-2",
+			Path:        "/app-2/v1/events",
+			Outcome:     DecisionOutcomeRejected,
+			Status:      http.StatusForbidden,
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/v1/decisions", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+
+		// when
+		decisionLog.ServeHTTP(recorder, req)
+
+		// then
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/json;charset=UTF-8", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(t, `[
+			{"timestamp":"2023-05-01T12:00:00Z","application":"app-1","path":"/app-1/events","destination":"cloud-events","outcome":"proxied","status":200},
+			{"timestamp":"2023-05-01T12:00:01Z","application":"app-2","path":"/app-2/v1/events","outcome":"rejected","status":403}
+		]`, recorder.Body.String())
+	})
+
+	t.Run("should dump empty list without decisions", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodGet, "/v1/decisions", nil)
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+
+		// when
+		NewDecisionLog(2).ServeHTTP(recorder, req)
+
+		// then
+		assert.JSONEq(t, `[]`, recorder.Body.String())
+	})
+}
+
+func TestProxyHandler_DecisionLog(t *testing.T) {
+	t.Run("should record proxied and rejected requests", func(t *testing.T) {
+		// given
+		decisionLog := NewDecisionLog(10)
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithDecisionLog(decisionLog))
+		h.setUpstreamStatus(http.StatusAccepted)
+
+		// when
+		h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+		h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, "other-application"))
+		h.do(http.MethodPost, fmt.Sprintf("/%s/unknown", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		decisions := decisionLog.Decisions()
+		require.Len(t, decisions, 3)
+
+		assert.Equal(t, applicationName, decisions[0].Application)
+		assert.Equal(t, fmt.Sprintf("/%s/events", applicationName), decisions[0].Path)
+		assert.Equal(t, DestinationCloudEvents, decisions[0].Destination)
+		assert.Equal(t, DecisionOutcomeProxied, decisions[0].Outcome)
+		assert.Equal(t, http.StatusAccepted, decisions[0].Status)
+		assert.False(t, decisions[0].Timestamp.IsZero())
+
+		assert.Equal(t, Destination(""), decisions[1].Destination)
+		assert.Equal(t, DecisionOutcomeRejected, decisions[1].Outcome)
+		assert.Equal(t, http.StatusForbidden, decisions[1].Status)
+
+		assert.Equal(t, DecisionOutcomeRejected, decisions[2].Outcome)
+		assert.Equal(t, http.StatusNotFound, decisions[2].Status)
+	})
+}
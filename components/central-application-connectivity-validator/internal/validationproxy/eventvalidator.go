@@ -0,0 +1,113 @@
+package validationproxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const (
+	cloudEventsStructuredContentType = "application/cloudevents+json"
+	cloudEventsBatchContentType      = "application/cloudevents-batch+json"
+)
+
+// DefaultMaxEventBodySize is the default maximum size in bytes of the structured events read for the validation
+const DefaultMaxEventBodySize = 1 << 20
+
+var requiredCloudEventAttributes = []string{"id", "source", "type"}
+
+// EventValidator decides whether the event sent to the events destination can be forwarded
+type EventValidator interface {
+	Validate(r *http.Request) error
+}
+
+type cloudEventValidator struct {
+	maxBodySize int64
+}
+
+// NewCloudEventValidator creates EventValidator which checks that the CloudEvent has the required id, source, and type
+// attributes. Both the binary and the structured content modes are supported, as well as batches of structured events.
+// The structured events longer than maxBodySize bytes are rejected with *http.MaxBytesError, a non-positive
+// maxBodySize uses DefaultMaxEventBodySize.
+func NewCloudEventValidator(maxBodySize int64) EventValidator {
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxEventBodySize
+	}
+	return cloudEventValidator{maxBodySize: maxBodySize}
+}
+
+func (v cloudEventValidator) Validate(r *http.Request) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case cloudEventsStructuredContentType:
+		var event map[string]interface{}
+		if err := decodeBody(r, v.maxBodySize, &event); err != nil {
+			return err
+		}
+		return validateStructuredEvent(event)
+	case cloudEventsBatchContentType:
+		var events []map[string]interface{}
+		if err := decodeBody(r, v.maxBodySize, &events); err != nil {
+			return err
+		}
+		for i, event := range events {
+			if err := validateStructuredEvent(event); err != nil {
+				return fmt.Errorf("event %d in batch: %s", i, err)
+			}
+		}
+		return nil
+	default:
+		return validateBinaryEvent(r.Header)
+	}
+}
+
+func validateStructuredEvent(event map[string]interface{}) error {
+	var missing []string
+	for _, attribute := range requiredCloudEventAttributes {
+		if value, ok := event[attribute].(string); !ok || value == "" {
+			missing = append(missing, attribute)
+		}
+	}
+	return missingAttributesError(missing)
+}
+
+func validateBinaryEvent(header http.Header) error {
+	var missing []string
+	for _, attribute := range requiredCloudEventAttributes {
+		if header.Get("ce-"+attribute) == "" {
+			missing = append(missing, attribute)
+		}
+	}
+	return missingAttributesError(missing)
+}
+
+func missingAttributesError(missing []string) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("missing required attributes: %s", strings.Join(missing, ", "))
+}
+
+// decodeBody decodes the JSON body of at most maxBodySize bytes and restores it, so it can still be forwarded
+func decodeBody(r *http.Request, maxBodySize int64, v interface{}) error {
+	if r.Body == nil {
+		return fmt.Errorf("empty body")
+	}
+
+	// the validator has no response writer, the handler answers the *http.MaxBytesError itself
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodySize))
+	if err != nil {
+		return fmt.Errorf("while reading body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("while decoding body: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package validationproxy
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudEventValidator(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		header          map[string]string
+		body            string
+		valid           bool
+	}{
+		{
+			caseDescription: "binary event with required attributes",
+			header:          map[string]string{"ce-id": "1", "ce-source": "/default/app", "ce-type": "order.created.v1", "Content-Type": "application/json"},
+			body:            `{"orderId":"1"}`,
+			valid:           true,
+		},
+		{
+			caseDescription: "binary event without type",
+			header:          map[string]string{"ce-id": "1", "ce-source": "/default/app", "Content-Type": "application/json"},
+			body:            `{"orderId":"1"}`,
+			valid:           false,
+		},
+		{
+			caseDescription: "structured event with required attributes",
+			header:          map[string]string{"Content-Type": "application/cloudevents+json; charset=utf-8"},
+			body:            `{"specversion":"1.0","id":"1","source":"/default/app","type":"order.created.v1"}`,
+			valid:           true,
+		},
+		{
+			caseDescription: "structured event with empty id",
+			header:          map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:            `{"specversion":"1.0","id":"","source":"/default/app","type":"order.created.v1"}`,
+			valid:           false,
+		},
+		{
+			caseDescription: "structured event with malformed body",
+			header:          map[string]string{"Content-Type": "application/cloudevents+json"},
+			body:            `{"id":`,
+			valid:           false,
+		},
+		{
+			caseDescription: "batch with one event without source",
+			header:          map[string]string{"Content-Type": "application/cloudevents-batch+json"},
+			body:            `[{"id":"1","source":"/default/app","type":"order.created.v1"},{"id":"2","type":"order.created.v1"}]`,
+			valid:           false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should validate "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			req, err := http.NewRequest(http.MethodPost, "/events", strings.NewReader(testCase.body))
+			require.NoError(t, err)
+			for key, value := range testCase.header {
+				req.Header.Set(key, value)
+			}
+
+			// when
+			err = NewCloudEventValidator(0).Validate(req)
+
+			// then
+			assert.Equal(t, testCase.valid, err == nil)
+			body, readErr := io.ReadAll(req.Body)
+			require.NoError(t, readErr)
+			assert.Equal(t, testCase.body, string(body))
+		})
+	}
+}
+
+func TestCloudEventValidator_MaxBodySize(t *testing.T) {
+	const event = `{"specversion":"1.0","id":"1","source":"/default/app","type":"order.created.v1"}`
+
+	t.Run("should reject structured event longer than the maximum body size", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodPost, "/events", strings.NewReader(event))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+
+		// when
+		err = NewCloudEventValidator(int64(len(event) - 1)).Validate(req)
+
+		// then
+		var tooLarge *http.MaxBytesError
+		require.True(t, errors.As(err, &tooLarge))
+		assert.Equal(t, int64(len(event)-1), tooLarge.Limit)
+	})
+
+	t.Run("should accept structured event of exactly the maximum body size", func(t *testing.T) {
+		// given
+		req, err := http.NewRequest(http.MethodPost, "/events", strings.NewReader(event))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/cloudevents+json")
+
+		// when
+		err = NewCloudEventValidator(int64(len(event))).Validate(req)
+
+		// then
+		require.NoError(t, err)
+		body, readErr := io.ReadAll(req.Body)
+		require.NoError(t, readErr)
+		assert.Equal(t, event, string(body))
+	})
+}
@@ -1,13 +1,18 @@
 package validationproxy
 
 import (
+	"context"
 	"crypto/x509/pkix"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httptools"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,14 +20,80 @@ import (
 
 	"github.com/kyma-project/kyma/common/logging/logger"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/apperrors"
+	gocache "github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
 	CertificateInfoHeader = "X-Forwarded-Client-Cert"
 
+	xForwardedForHeader = "X-Forwarded-For"
+
 	handlerName = "validation_proxy_handler"
 )
 
+// Machine-readable error codes returned in the error responses
+const (
+	ErrorCodeCertificateHeaderNotFound = "CERT_HEADER_NOT_FOUND"
+	ErrorCodeCertificateHeaderEmpty    = "CERT_HEADER_EMPTY"
+	ErrorCodeCertificateHeaderTooLarge = "CERT_HEADER_TOO_LARGE"
+	ErrorCodeAppNameNotSpecified       = "APP_NAME_NOT_SPECIFIED"
+	ErrorCodeAppNotFound               = "APP_NOT_FOUND"
+	ErrorCodeClientIDsUnavailable      = "CLIENT_IDS_UNAVAILABLE"
+	ErrorCodeForbiddenNoSubject        = "FORBIDDEN_NO_SUBJECT"
+	ErrorCodeDestinationNotFound       = "DESTINATION_NOT_FOUND"
+	ErrorCodeDestinationNotAllowed     = "DESTINATION_NOT_ALLOWED"
+	ErrorCodeMethodNotAllowed          = "METHOD_NOT_ALLOWED"
+	ErrorCodeInvalidEvent              = "INVALID_EVENT"
+	ErrorCodeEventTooLarge             = "EVENT_TOO_LARGE"
+	ErrorCodeAppDisabled               = "APP_DISABLED"
+	ErrorCodeConcurrencyLimitReached   = "CONCURRENCY_LIMIT_REACHED"
+	ErrorCodeCircuitOpen               = "CIRCUIT_OPEN"
+	ErrorCodeUpstreamUnreachable       = "UPSTREAM_UNREACHABLE"
+	ErrorCodeUpstreamTimeout           = "UPSTREAM_TIMEOUT"
+)
+
+// retryAfterSeconds is sent in the Retry-After header when the concurrency limit is reached,
+// and when the destination signals backpressure without the Retry-After header
+const retryAfterSeconds = "1"
+
+// DefaultClientIDFetchTimeout bounds the shared fetch of the fallback client IDs, which is not cancelled with the requests
+const DefaultClientIDFetchTimeout = 10 * time.Second
+
+// DefaultClientIDCacheTTL is the default time for which the fallback client IDs of an application are reused
+const DefaultClientIDCacheTTL = time.Minute
+
+// Destination identifies the upstream to which the requests are proxied
+type Destination string
+
+const (
+	// DestinationLegacyEvents is the legacy events endpoint of the Eventing Publisher Proxy
+	DestinationLegacyEvents Destination = "legacy-events"
+	// DestinationCloudEvents is the publish endpoint of the Eventing Publisher Proxy
+	DestinationCloudEvents Destination = "cloud-events"
+)
+
+// SubjectValidationMode defines how multiple subjects presented in the certificate header are evaluated
+type SubjectValidationMode string
+
+const (
+	// SubjectValidationModeAny accepts the request if at least one of the presented subjects is valid
+	SubjectValidationModeAny SubjectValidationMode = "any"
+	// SubjectValidationModeAll accepts the request only if every presented subject is valid
+	SubjectValidationModeAll SubjectValidationMode = "all"
+)
+
+// ClientIDFetchFailurePolicy defines how the requests are handled when the application client IDs cannot be fetched
+type ClientIDFetchFailurePolicy string
+
+const (
+	// ClientIDFetchFailurePolicyFailClosed rejects the request
+	ClientIDFetchFailurePolicyFailClosed ClientIDFetchFailurePolicy = "fail-closed"
+	// ClientIDFetchFailurePolicyFailOpenToCN validates the certificate subject against the application name,
+	// as for the applications without client IDs
+	ClientIDFetchFailurePolicyFailOpenToCN ClientIDFetchFailurePolicy = "fail-open-to-cn"
+)
+
 type ProxyHandler interface {
 	ProxyAppConnectorRequests(w http.ResponseWriter, r *http.Request)
 }
@@ -30,21 +101,304 @@ type ProxyHandler interface {
 type Cache interface {
 	Get(k string) (interface{}, bool)
 	Set(k string, x interface{}, d time.Duration)
+	ItemCount() int
 }
 
 type proxyHandler struct {
-	eventingPublisherHost string
+	eventingPublisherHost   string
+	eventingDestinationPath string
 
 	legacyEventsProxy *httputil.ReverseProxy
 	cloudEventsProxy  *httputil.ReverseProxy
+	eventsAPIRoutes   []*eventsAPIRoute
+
+	eventsAPIRouteConfigs []EventsAPIRoute
+	destinationOptions    []func(*proxyHandler)
+
+	fallbackDestination Destination
+
+	log                   *logger.Logger
+	subjectDelimiter      string
+	subjectAttributes     SubjectAttributes
+	requiredAttributes    []string
+	maxCertHeaderLength   int
+	subjectValidationMode SubjectValidationMode
+	subjectValidator      SubjectValidator
+	sanValidationMode     SANValidationMode
+	sanURIPrefix          string
+
+	defaultOrganization       string
+	defaultOrganizationalUnit string
+
+	cache            Cache
+	clientIDResolver ClientIDResolver
+	clientIDSource   ClientIDSource
+	clientIDFetches  singleflight.Group
+	clientIDFailure  ClientIDFetchFailurePolicy
+	clientIDTimeout  time.Duration
+	clientIDCacheTTL time.Duration
+	clientIDCache    *gocache.Cache
+	eventValidator   EventValidator
+
+	pathRedactor *pathRedactor
+	logSampler   *logSampler
+	logStrategy  bool
+	decisionLog  *DecisionLog
+	appCounters  *ApplicationCounters
+
+	disabledDestinations map[Destination]bool
+	preservedHosts       map[Destination]bool
+	allowedMethods       map[Destination][]string
+
+	concurrencyLimit            semaphore
+	destinationConcurrencyLimit map[Destination]semaphore
+	circuitBreakers             map[Destination]*circuitBreaker
+}
+
+// semaphore limits the number of concurrent requests, the nil semaphore is unlimited
+type semaphore chan struct{}
+
+func (s semaphore) tryAcquire() bool {
+	if s == nil {
+		return true
+	}
+	select {
+	case s <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+type Option func(*proxyHandler)
+
+// destinationOption runs the option after the proxies of all the destinations, including the destinations of
+// the events API routes, are built, so that it applies regardless of its order among the options
+func destinationOption(f func(*proxyHandler)) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.destinationOptions = append(p.destinationOptions, f)
+	}
+}
+
+// WithResponseHeaderTimeout sets the time to wait for the response headers of the destination, timed out requests are answered with 504
+func WithResponseHeaderTimeout(destination Destination, timeout time.Duration) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		proxy := p.destinationProxy(destination)
+		if proxy == nil {
+			return
+		}
+		if transport, ok := proxy.Transport.(*http.Transport); ok {
+			transport.ResponseHeaderTimeout = timeout
+		}
+	})
+}
+
+// WithFlushInterval sets the interval of flushing the destination response to the client while copying it. Responses
+// without known length and event streams are always flushed immediately.
+func WithFlushInterval(destination Destination, interval time.Duration) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		proxy := p.destinationProxy(destination)
+		if proxy == nil {
+			return
+		}
+		proxy.FlushInterval = interval
+	})
+}
+
+// WithEventsAPIRoutes adds routes to additional destinations, which are matched before the default routes
+func WithEventsAPIRoutes(routes ...EventsAPIRoute) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.eventsAPIRouteConfigs = append(p.eventsAPIRouteConfigs, routes...)
+	}
+}
+
+// WithFallbackDestination proxies the requests whose path matches no route to the destination, instead of answering them with 404.
+// Unknown destinations are ignored.
+func WithFallbackDestination(destination Destination) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		if p.destinationProxy(destination) != nil {
+			p.fallbackDestination = destination
+		}
+	})
+}
+
+// WithDisabledDestinations disables proxying to the destinations, requests targeting them are answered with 404
+func WithDisabledDestinations(destinations ...Destination) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		for _, destination := range destinations {
+			p.disabledDestinations[destination] = true
+		}
+	}
+}
 
-	log          *logger.Logger
-	subjectRegex *regexp.Regexp
+// WithOriginalHost forwards the Host header of the client to the destinations, instead of the host of the destination
+func WithOriginalHost(destinations ...Destination) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		for _, destination := range destinations {
+			proxy := p.destinationProxy(destination)
+			if proxy == nil || p.preservedHosts[destination] {
+				continue
+			}
+			preserveRequestHost(proxy)
+			p.preservedHosts[destination] = true
+		}
+	})
+}
 
-	cache Cache
+// WithAllowedMethods restricts the HTTP methods proxied to the destination, other methods are answered with 405
+func WithAllowedMethods(destination Destination, methods ...string) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.allowedMethods[destination] = methods
+	}
 }
 
-type option func(*proxyHandler)
+// WithMaxConcurrentRequests limits the number of requests proxied concurrently to all destinations,
+// requests above the limit are answered with 503. A non-positive limit leaves the requests unlimited.
+func WithMaxConcurrentRequests(limit int) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if limit <= 0 {
+			p.concurrencyLimit = nil
+			return
+		}
+		p.concurrencyLimit = make(semaphore, limit)
+	}
+}
+
+// WithMaxConcurrentDestinationRequests limits the number of requests proxied concurrently to the destination,
+// requests above the limit are answered with 503. A non-positive limit leaves the requests unlimited.
+func WithMaxConcurrentDestinationRequests(destination Destination, limit int) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if limit <= 0 {
+			delete(p.destinationConcurrencyLimit, destination)
+			return
+		}
+		p.destinationConcurrencyLimit[destination] = make(semaphore, limit)
+	}
+}
+
+// WithCircuitBreaker stops proxying to the destination for openTimeout after failureThreshold consecutive failed requests,
+// the rejected requests are answered with 503. Afterwards a single request probes whether the destination recovered.
+// Requests answered by the destination with 5xx, failed, or timed out count as failures.
+func WithCircuitBreaker(destination Destination, failureThreshold int, openTimeout time.Duration) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.circuitBreakers[destination] = newCircuitBreaker(failureThreshold, openTimeout)
+	}
+}
+
+// WithEventValidator sets the validator of the events sent to the cloud events destination, invalid events are answered with 400
+func WithEventValidator(validator EventValidator) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.eventValidator = validator
+	}
+}
+
+// WithMaxCertificateHeaderLength rejects the requests whose certificate header is longer than length bytes with 431
+// before parsing it, 0 means no limit
+func WithMaxCertificateHeaderLength(length int) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.maxCertHeaderLength = length
+	}
+}
+
+// WithSubjectDelimiter sets the delimiter separating the attributes of the certificate subject, by default a comma.
+// The empty delimiter keeps the comma.
+func WithSubjectDelimiter(delimiter string) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if delimiter != "" {
+			p.subjectDelimiter = delimiter
+		}
+	}
+}
+
+// SubjectAttributes names the certificate subject attributes carrying the application identity, which is validated
+// as the Common Name, and the tenant and the group, which are validated as the organization and the organizational unit
+type SubjectAttributes struct {
+	Identity           string `json:"identity"`
+	Organization       string `json:"organization"`
+	OrganizationalUnit string `json:"organizationalUnit"`
+}
+
+// DefaultSubjectAttributes reads the identity from CN, the tenant from O, and the group from OU
+var DefaultSubjectAttributes = SubjectAttributes{Identity: "CN", Organization: "O", OrganizationalUnit: "OU"}
+
+// WithSubjectAttributes sets the certificate subject attributes carrying the identity, the tenant, and the group,
+// the empty attributes keep their defaults. The rejected requests name the mismatching attribute.
+func WithSubjectAttributes(attributes SubjectAttributes) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if attributes.Identity != "" {
+			p.subjectAttributes.Identity = attributes.Identity
+		}
+		if attributes.Organization != "" {
+			p.subjectAttributes.Organization = attributes.Organization
+		}
+		if attributes.OrganizationalUnit != "" {
+			p.subjectAttributes.OrganizationalUnit = attributes.OrganizationalUnit
+		}
+	}
+}
+
+// WithRequiredSubjectAttributes rejects the certificate subjects which do not present all the attributes with non-empty values,
+// for example O and OU. The default organization and organizational unit do not satisfy the requirement.
+func WithRequiredSubjectAttributes(attributes ...string) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.requiredAttributes = attributes
+	}
+}
+
+// WithLocationRewrite rewrites the Location headers of the destination responses pointing at internal hosts to
+// publicPathPrefix followed by the redirect path. An empty publicPathPrefix strips such Location headers.
+func WithLocationRewrite(destination Destination, publicPathPrefix string) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		proxy := p.destinationProxy(destination)
+		if proxy == nil {
+			return
+		}
+		appendResponseOptions(proxy, withRewrittenInternalLocation(p.eventingPublisherHost, publicPathPrefix))
+	})
+}
+
+// WithRequestHooks runs the hooks on the requests proxied to the destination, after the built-in request rewrites,
+// for example to add an authorization header. Unknown destinations are ignored.
+func WithRequestHooks(destination Destination, hooks ...func(*http.Request)) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		proxy := p.destinationProxy(destination)
+		if proxy == nil {
+			return
+		}
+		for _, hook := range hooks {
+			appendRequestOptions(proxy, hook)
+		}
+	})
+}
+
+// WithTrustedProxyHops keeps only the X-Forwarded-For entries appended by the given number of trusted proxies in front of the validator.
+// The entries sent by the client are dropped, and the address of the direct peer is appended as usual.
+// A negative number of hops keeps all entries.
+func WithTrustedProxyHops(hops int) func(*proxyHandler) {
+	return destinationOption(func(p *proxyHandler) {
+		if hops < 0 {
+			return
+		}
+		appendRequestOptions(p.legacyEventsProxy, withTrustedXFwdFor(hops))
+		appendRequestOptions(p.cloudEventsProxy, withTrustedXFwdFor(hops))
+		for _, route := range p.eventsAPIRoutes {
+			appendRequestOptions(route.proxy, withTrustedXFwdFor(hops))
+		}
+	})
+}
+
+// WithSubjectValidationMode sets the mode used to evaluate multiple subjects, SubjectValidationModeAny is used by default
+func WithSubjectValidationMode(mode SubjectValidationMode) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.subjectValidationMode = mode
+	}
+}
 
 func WithCEProxyTransport(t http.RoundTripper) func(*proxyHandler) {
 	return func(p *proxyHandler) {
@@ -52,163 +406,687 @@ func WithCEProxyTransport(t http.RoundTripper) func(*proxyHandler) {
 	}
 }
 
-func NewProxyHandler(
-	eventingPublisherHost string,
-	eventingDestinationPath string,
-	cache Cache,
-	log *logger.Logger,
-	ops ...option) ProxyHandler {
+// WithSANURIValidation validates the URI Subject Alternative Names of the certificate in the given mode.
+// A valid URI is uriPrefix followed by the application name.
+func WithSANURIValidation(mode SANValidationMode, uriPrefix string) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.sanValidationMode = mode
+		p.sanURIPrefix = uriPrefix
+	}
+}
 
-	out := proxyHandler{
-		eventingPublisherHost: eventingPublisherHost,
+// WithSubjectValidator replaces the default validation of certificate subjects
+func WithSubjectValidator(v SubjectValidator) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.subjectValidator = v
+	}
+}
+
+// WithLogSampling writes the informational logs of 1 in rate requests, the errors are always logged
+func WithLogSampling(rate int) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.logSampler = newLogSampler(rate)
+	}
+}
+
+// WithPathRedaction replaces the request path segments matching any of the patterns with *** in the logs
+func WithPathRedaction(patterns ...*regexp.Regexp) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.pathRedactor.patterns = append(p.pathRedactor.patterns, patterns...)
+	}
+}
+
+// WithClientIDResolver replaces resolving the client IDs from the cache of Application resources,
+// the cache still provides the routing of the application requests
+func WithClientIDResolver(r ClientIDResolver) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.clientIDResolver = r
+	}
+}
 
-		legacyEventsProxy: createReverseProxy(log, eventingPublisherHost, withEmptyRequestHost, withEmptyXFwdClientCert, withHTTPScheme),
-		cloudEventsProxy:  createReverseProxy(log, eventingPublisherHost, withRewriteBaseURL(eventingDestinationPath), withEmptyRequestHost, withEmptyXFwdClientCert, withHTTPScheme),
+// WithClientIDSource sets the source of client IDs consulted for applications without Compass client IDs, either without
+// Compass metadata or with Compass metadata without authentication client IDs
+func WithClientIDSource(s ClientIDSource) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.clientIDSource = s
+	}
+}
 
-		cache:        cache,
-		log:          log,
-		subjectRegex: regexp.MustCompile(`Subject="(.*?)"`),
+// WithClientIDCacheTTL sets the time for which the client IDs fetched from ClientIDSource are reused for the requests
+// of the application, by default DefaultClientIDCacheTTL. A non-positive TTL keeps the default.
+func WithClientIDCacheTTL(ttl time.Duration) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if ttl > 0 {
+			p.clientIDCacheTTL = ttl
+		}
+	}
+}
+
+// WithClientIDFetchTimeout sets the time after which fetching the client IDs from ClientIDSource fails,
+// by default DefaultClientIDFetchTimeout. A non-positive timeout keeps the default.
+func WithClientIDFetchTimeout(timeout time.Duration) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		if timeout > 0 {
+			p.clientIDTimeout = timeout
+		}
+	}
+}
+
+// WithDefaultSubjectOrganization sets the organization and the organizational unit assumed for the certificate subjects
+// presenting none, before they are validated. An empty default leaves the field empty.
+func WithDefaultSubjectOrganization(organization, organizationalUnit string) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.defaultOrganization = organization
+		p.defaultOrganizationalUnit = organizationalUnit
+	}
+}
+
+// WithValidationStrategyLog enables logging the strategy which approved the identity of each accepted request,
+// the log is written regardless of the log sampling
+func WithValidationStrategyLog() func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.logStrategy = true
+	}
+}
+
+// WithDecisionLog records the decision about each request in the log, which can be shared by several handlers
+func WithDecisionLog(decisionLog *DecisionLog) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.decisionLog = decisionLog
+	}
+}
+
+// WithApplicationCounters counts the requests of each application in the counters, which can be shared by several handlers
+func WithApplicationCounters(counters *ApplicationCounters) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.appCounters = counters
+	}
+}
+
+// WithClientIDFetchFailurePolicy sets the handling of the requests whose application client IDs cannot be fetched,
+// by default they are rejected
+func WithClientIDFetchFailurePolicy(policy ClientIDFetchFailurePolicy) func(*proxyHandler) {
+	return func(p *proxyHandler) {
+		p.clientIDFailure = policy
+	}
+}
+
+// NewProxyHandlerFromConfig creates ProxyHandler proxying to the destinations of the config. It returns an error when
+// the eventing publisher host or the eventing destination path are malformed, instead of failing at request time.
+func NewProxyHandlerFromConfig(config ProxyConfig, cache Cache, log *logger.Logger, ops ...Option) (ProxyHandler, error) {
+	if err := validateProxyParameters(config.EventingPublisherHost, config.EventingDestinationPath); err != nil {
+		return nil, err
+	}
+
+	return newProxyHandler(config, cache, log, ops...), nil
+}
+
+func newProxyHandler(config ProxyConfig, cache Cache, log *logger.Logger, ops ...Option) *proxyHandler {
+	redactor := &pathRedactor{}
+
+	out := proxyHandler{
+		eventingPublisherHost:   config.EventingPublisherHost,
+		eventingDestinationPath: config.EventingDestinationPath,
+
+		legacyEventsProxy: createReverseProxy(log, redactor, config.EventingPublisherHost, withEmptyRequestHost, withEmptyXFwdClientCert, withHTTPScheme),
+		cloudEventsProxy:  createReverseProxy(log, redactor, config.EventingPublisherHost, withRewriteBaseURL(config.EventingDestinationPath), withEmptyRequestHost, withEmptyXFwdClientCert, withHTTPScheme),
+
+		cache:                 cache,
+		clientIDResolver:      NewCacheClientIDResolver(cache),
+		clientIDFailure:       ClientIDFetchFailurePolicyFailClosed,
+		clientIDTimeout:       DefaultClientIDFetchTimeout,
+		clientIDCacheTTL:      DefaultClientIDCacheTTL,
+		log:                   log,
+		subjectDelimiter:      ",",
+		subjectAttributes:     DefaultSubjectAttributes,
+		subjectValidationMode: SubjectValidationModeAny,
+		subjectValidator:      NewDefaultSubjectValidator(),
+		sanValidationMode:     SANValidationModeDisabled,
+		pathRedactor:          redactor,
+		disabledDestinations:  map[Destination]bool{},
+		preservedHosts:        map[Destination]bool{},
+		allowedMethods:        map[Destination][]string{},
+
+		destinationConcurrencyLimit: map[Destination]semaphore{},
+		circuitBreakers:             map[Destination]*circuitBreaker{},
 	}
 
 	for _, f := range ops {
 		f(&out)
 	}
+	for _, route := range out.eventsAPIRouteConfigs {
+		out.eventsAPIRoutes = append(out.eventsAPIRoutes, newEventsAPIRoute(route, log, redactor))
+	}
+	for _, f := range out.destinationOptions {
+		f(&out)
+	}
+	if out.clientIDSource != nil {
+		out.clientIDCache = gocache.New(out.clientIDCacheTTL, out.clientIDCacheTTL)
+	}
+
+	log.WithContext().With("handler", handlerName).With("config", out.config()).Infof("Proxy handler configured")
 
 	return &out
 }
 
+func validateProxyParameters(eventingPublisherHost, eventingDestinationPath string) error {
+	hostURL, err := url.Parse("//" + eventingPublisherHost)
+	if err != nil || eventingPublisherHost == "" || hostURL.Host != eventingPublisherHost || hostURL.Hostname() == "" || hostURL.User != nil {
+		return fmt.Errorf("eventing publisher host '%s' should be a host with an optional port", eventingPublisherHost)
+	}
+	if port := hostURL.Port(); port != "" {
+		if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+			return fmt.Errorf("eventing publisher host '%s' should have a valid port", eventingPublisherHost)
+		}
+	}
+
+	pathURL, err := url.Parse(eventingDestinationPath)
+	if err != nil || !strings.HasPrefix(eventingDestinationPath, "/") || pathURL.Path != eventingDestinationPath {
+		return fmt.Errorf("eventing destination path '%s' should be an absolute path without query", eventingDestinationPath)
+	}
+
+	return nil
+}
+
+// NewProxyHandler creates ProxyHandler proxying to the eventing publisher host and the eventing destination path.
+// The host and the path are not validated, the requests to the malformed ones fail when they are proxied.
+//
+// Deprecated: use NewProxyHandlerFromConfig, which returns the error of the malformed host or path
+func NewProxyHandler(
+	eventingPublisherHost string,
+	eventingDestinationPath string,
+	cache Cache,
+	log *logger.Logger,
+	ops ...Option) ProxyHandler {
+
+	return newProxyHandler(ProxyConfig{
+		EventingPublisherHost:   eventingPublisherHost,
+		EventingDestinationPath: eventingDestinationPath,
+	}, cache, log, ops...)
+}
+
 func (ph *proxyHandler) ProxyAppConnectorRequests(w http.ResponseWriter, r *http.Request) {
+	if ph.decisionLog == nil {
+		ph.proxyAppConnectorRequests(w, r)
+		return
+	}
+
+	decision := &ProxyDecision{
+		Timestamp:   time.Now(),
+		Application: mux.Vars(r)["application"],
+		Path:        ph.pathRedactor.redact(r.URL.Path),
+		Outcome:     DecisionOutcomeRejected,
+	}
+	recorder := &statusRecorder{ResponseWriter: w}
+	ph.proxyAppConnectorRequests(recorder, r.WithContext(withProxyDecision(r.Context(), decision)))
+	decision.Status = recorder.status
+	ph.decisionLog.add(*decision)
+}
+
+func (ph *proxyHandler) proxyAppConnectorRequests(w http.ResponseWriter, r *http.Request) {
+	// the ingress omits the header when it is misconfigured, and forwards it empty when the client sent no certificate
+	if _, present := r.Header[http.CanonicalHeaderKey(CertificateInfoHeader)]; !present {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, r, apperrors.Internal("%s header not found", CertificateInfoHeader).WithErrorCode(ErrorCodeCertificateHeaderNotFound))
+		return
+	}
+
 	certInfoData := r.Header.Get(CertificateInfoHeader)
-	if certInfoData == "" {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, apperrors.Internal("%s header not found", CertificateInfoHeader))
+	if ph.maxCertHeaderLength > 0 && len(certInfoData) > ph.maxCertHeaderLength {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, r, apperrors.HeaderTooLarge("%s header is longer than %d bytes", CertificateInfoHeader, ph.maxCertHeaderLength).WithErrorCode(ErrorCodeCertificateHeaderTooLarge))
+		return
+	}
+
+	if strings.TrimSpace(certInfoData) == "" {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, r, apperrors.Unauthorized("%s header is empty, client certificate not provided", CertificateInfoHeader).WithErrorCode(ErrorCodeCertificateHeaderEmpty))
 		return
 	}
 
 	applicationName := mux.Vars(r)["application"]
 	if applicationName == "" {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, apperrors.BadRequest("application name not specified"))
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName), w, r, apperrors.BadRequest("application name not specified").WithErrorCode(ErrorCodeAppNameNotSpecified))
 		return
 	}
 
-	ph.log.WithTracing(r.Context()).With("handler", handlerName).With("application", applicationName).With("proxyPath", r.URL.Path).Infof("Proxying request for application...")
+	sampled := ph.logSampler.sample()
+	r = r.WithContext(withLogSampled(r.Context(), sampled))
+	if sampled {
+		ph.log.WithTracing(r.Context()).With("handler", handlerName).With("application", applicationName).With("proxyPath", ph.pathRedactor.redact(r.URL.Path)).Infof("Proxying request for application...")
+	}
 
-	applicationClientIDs, err := ph.getCompassMetadataClientIDs(applicationName)
+	applicationClientIDs, err := ph.resolveClientIDs(r.Context(), applicationName)
 	if err != nil {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, apperrors.NotFound("while getting application ClientIds: %s", err))
+		applicationClientIDs, err = ph.clientIDsAfterFetchFailure(r.Context(), applicationName, err)
+	}
+	if err != nil {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, err)
 		return
 	}
 
-	subjects := ph.extractSubjects(certInfoData)
+	if len(applicationClientIDs) == 0 && ph.clientIDSource != nil {
+		applicationClientIDs, err = ph.getFallbackClientIDs(r.Context(), applicationName)
+		if err != nil {
+			applicationClientIDs, err = ph.clientIDsAfterFetchFailure(r.Context(), applicationName, err)
+		}
+		if err != nil {
+			httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, err)
+			return
+		}
+	}
+
+	strategy, validationErr := ph.validateIdentity(certInfoData, applicationClientIDs, applicationName)
+	if validationErr != nil {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName).With("reason", validationErr.Error()), w, r, apperrors.Forbidden("no valid subject found%s", subjectRejectionSummary(validationErr)).WithErrorCode(ErrorCodeForbiddenNoSubject))
+		return
+	}
+	if ph.logStrategy {
+		ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName).With("validationStrategy", strategy).Infof("Identity of the request approved")
+	}
+	// only the requests with the approved identity are counted, so that the clients cannot fill the counters
+	// with made-up application names
+	ph.appCounters.record(applicationName)
 
-	if !hasValidSubject(subjects, applicationClientIDs, applicationName) {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, apperrors.Forbidden("no valid subject found"))
+	if ph.isProxyDisabled(applicationName) {
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.Unavailable("proxying requests of application %s is disabled", applicationName).WithErrorCode(ErrorCodeAppDisabled))
 		return
 	}
 
-	reverseProxy, err := ph.mapRequestToProxy(r.URL.Path, applicationName)
+	destination, err := ph.mapRequestToDestination(r.URL.Path, applicationName)
 	if err != nil {
-		httptools.RespondWithError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, err)
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, err)
 		return
 	}
+	decision := proxyDecisionFrom(r.Context())
+	decision.setDestination(destination)
 
-	reverseProxy.ServeHTTP(w, r)
+	if allowedMethods, found := ph.allowedMethods[destination]; found && !contains(allowedMethods, r.Method) {
+		w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.MethodNotAllowed("method %s is not allowed for destination %s", r.Method, destination).WithErrorCode(ErrorCodeMethodNotAllowed))
+		return
+	}
+
+	if destination == DestinationCloudEvents && ph.eventValidator != nil {
+		if err := ph.eventValidator.Validate(r); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.PayloadTooLarge("event is longer than %d bytes", tooLarge.Limit).WithErrorCode(ErrorCodeEventTooLarge))
+				return
+			}
+			httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.BadRequest("invalid event: %s", err).WithErrorCode(ErrorCodeInvalidEvent))
+			return
+		}
+	}
+
+	if !ph.concurrencyLimit.tryAcquire() {
+		ph.respondConcurrencyLimitReached(w, r, applicationName, "proxy")
+		return
+	}
+	defer ph.concurrencyLimit.release()
+
+	destinationLimit := ph.destinationConcurrencyLimit[destination]
+	if !destinationLimit.tryAcquire() {
+		ph.respondConcurrencyLimitReached(w, r, applicationName, string(destination))
+		return
+	}
+	defer destinationLimit.release()
+
+	breaker := ph.circuitBreakers[destination]
+	if allowed, retryAfter := breaker.allow(); !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.Unavailable("destination %s is failing, circuit breaker is open", destination).WithErrorCode(ErrorCodeCircuitOpen))
+		return
+	}
+
+	decision.setProxied()
+	if breaker == nil {
+		ph.destinationProxy(destination).ServeHTTP(w, r)
+		return
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w}
+	defer func() {
+		// the proxy panics with http.ErrAbortHandler when copying the response fails, the breaker must still
+		// learn the result, otherwise it would stay half-open
+		p := recover()
+		switch {
+		case p != nil && r.Context().Err() == nil:
+			breaker.record(true)
+		case p != nil || recorder.status == 0:
+			breaker.abandon()
+		default:
+			breaker.record(recorder.status >= http.StatusInternalServerError)
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+	ph.destinationProxy(destination).ServeHTTP(recorder, r)
 }
 
-func (ph *proxyHandler) getCompassMetadataClientIDs(applicationName string) ([]string, apperrors.AppError) {
-	applicationClientIDs, found := ph.getClientIDsFromCache(applicationName)
-	if !found {
-		err := apperrors.NotFound("application data for name %s is not found in the cache. Please retry", applicationName)
-		return nil, err
+func (ph *proxyHandler) respondConcurrencyLimitReached(w http.ResponseWriter, r *http.Request, applicationName, limitName string) {
+	w.Header().Set("Retry-After", retryAfterSeconds)
+	httptools.RespondWithNegotiatedError(ph.log.WithTracing(r.Context()).With("handler", handlerName).With("applicationName", applicationName), w, r, apperrors.Unavailable("concurrency limit of %s reached", limitName).WithErrorCode(ErrorCodeConcurrencyLimitReached))
+}
+
+func (ph *proxyHandler) resolveClientIDs(ctx context.Context, applicationName string) ([]string, apperrors.AppError) {
+	applicationClientIDs, err := ph.clientIDResolver.ResolveClientIDs(ctx, applicationName)
+	if errors.Is(err, ErrApplicationNotFound) {
+		return nil, apperrors.NotFound("while getting application ClientIds: application data for name %s is not found. Please retry", applicationName).WithErrorCode(ErrorCodeAppNotFound)
+	}
+	if err != nil {
+		return nil, apperrors.Internal("while getting application ClientIds: %s", err).WithErrorCode(ErrorCodeClientIDsUnavailable)
 	}
 	return applicationClientIDs, nil
 }
 
-func (ph *proxyHandler) getClientIDsFromCache(applicationName string) ([]string, bool) {
+// clientIDsAfterFetchFailure returns the fetch error, or no client IDs when the policy fails open.
+// The applications unknown to the cache are rejected regardless of the policy.
+func (ph *proxyHandler) clientIDsAfterFetchFailure(ctx context.Context, applicationName string, err apperrors.AppError) ([]string, apperrors.AppError) {
+	if ph.clientIDFailure != ClientIDFetchFailurePolicyFailOpenToCN || err.Code() != apperrors.CodeInternal {
+		return nil, err
+	}
+
+	ph.log.WithTracing(ctx).With("handler", handlerName).With("applicationName", applicationName).Warnf("Client IDs unavailable, validating the subject against the application name: %s", err.Error())
+	return []string{}, nil
+}
+
+func (ph *proxyHandler) getFallbackClientIDs(ctx context.Context, applicationName string) ([]string, apperrors.AppError) {
+	// the fetched client IDs, also the empty ones, are reused for the TTL, so that the source is not read on every request
+	if clientIDs, found := ph.clientIDCache.Get(applicationName); found {
+		return clientIDs.([]string), nil
+	}
+
+	// concurrent requests of the same application share a single fetch, which is not cancelled with the request
+	// that started it, so that the other requests still get the result. The timeout releases the shared fetch
+	// of a source which never answers, so that later requests can start a new one.
+	result, err, _ := ph.clientIDFetches.Do(applicationName, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), ph.clientIDTimeout)
+		defer cancel()
+		clientIDs, err := ph.clientIDSource.GetClientIDs(fetchCtx, applicationName)
+		if err != nil {
+			return nil, err
+		}
+		ph.clientIDCache.SetDefault(applicationName, clientIDs)
+		return clientIDs, nil
+	})
+	if err != nil {
+		clientIDFetchErrors.Inc()
+		return nil, apperrors.Internal("while getting application ClientIds from fallback source: %s", err).WithErrorCode(ErrorCodeClientIDsUnavailable)
+	}
+	return result.([]string), nil
+}
+
+func (ph *proxyHandler) isProxyDisabled(applicationName string) bool {
 	appData, found := ph.cache.Get(applicationName)
 	if !found {
-		return []string{}, found
+		return false
 	}
 
-	appInfo := appData.(controller.CachedAppData)
-
-	return appInfo.ClientIDs, found
+	return appData.(controller.CachedAppData).ProxyDisabled
 }
 
-func (ph *proxyHandler) mapRequestToProxy(path string, applicationName string) (*httputil.ReverseProxy, apperrors.AppError) {
+func (ph *proxyHandler) mapRequestToDestination(path string, applicationName string) (Destination, apperrors.AppError) {
 
 	appData, found := ph.cache.Get(applicationName)
 
 	if !found {
-		return nil, apperrors.NotFound("application data for name %s is not found in the cache. Please retry", applicationName)
+		return "", apperrors.NotFound("application data for name %s is not found in the cache. Please retry", applicationName).WithErrorCode(ErrorCodeAppNotFound)
 	}
 
 	appInfo := appData.(controller.CachedAppData)
 
+	destination, found := ph.mapPathToEventsAPIRoute(path, applicationName)
+	if !found {
+		destination, found = mapPathToDestination(path, appInfo)
+	}
+	if !found && ph.fallbackDestination != "" {
+		destination, found = ph.fallbackDestination, true
+	}
+	if !found || ph.disabledDestinations[destination] {
+		return "", apperrors.NotFound("could not determine destination host, requested resource not found").WithErrorCode(ErrorCodeDestinationNotFound)
+	}
+	if appInfo.AllowedDestinations != nil && !contains(appInfo.AllowedDestinations, string(destination)) {
+		return "", apperrors.Forbidden("application %s is not allowed to use destination %s", applicationName, destination).WithErrorCode(ErrorCodeDestinationNotAllowed)
+	}
+
+	return destination, nil
+}
+
+func (ph *proxyHandler) mapPathToEventsAPIRoute(path, applicationName string) (Destination, bool) {
+	for _, route := range ph.eventsAPIRoutes {
+		if route.matches(path, applicationName) {
+			return route.Destination, true
+		}
+	}
+	return "", false
+}
+
+func mapPathToDestination(path string, appInfo controller.CachedAppData) (Destination, bool) {
 	switch {
 
 	// legacy-events reaching /{application}/v1/events are routed to /{application}/v1/events endpoint of event-publisher-proxy
 	case strings.HasPrefix(path, appInfo.AppPathPrefixV1):
-		return ph.legacyEventsProxy, nil
+		return DestinationLegacyEvents, true
 
 	// cloud-events reaching /{application}/v2/events or /{application}/events are routed to /publish endpoint of event-publisher-proxy
 	case strings.HasPrefix(path, appInfo.AppPathPrefixV2):
-		return ph.cloudEventsProxy, nil
+		return DestinationCloudEvents, true
 
 	// cloud-events reaching /{application}/events are routed to /publish endpoint of event-publisher-proxy
 	case strings.HasPrefix(path, appInfo.AppPathPrefixEvents):
-		return ph.cloudEventsProxy, nil
+		return DestinationCloudEvents, true
 	}
 
-	return nil, apperrors.NotFound("could not determine destination host, requested resource not found")
+	return "", false
 }
 
-func hasValidSubject(subjects, applicationClientIDs []string, appName string) bool {
-	subjectValidator := newSubjectValidator(applicationClientIDs, appName)
+func (ph *proxyHandler) destinationProxy(destination Destination) *httputil.ReverseProxy {
+	switch destination {
+	case DestinationLegacyEvents:
+		return ph.legacyEventsProxy
+	case DestinationCloudEvents:
+		return ph.cloudEventsProxy
+	}
+	for _, route := range ph.eventsAPIRoutes {
+		if route.Destination == destination {
+			return route.proxy
+		}
+	}
+	return nil
+}
 
-	for _, s := range subjects {
-		parsedSubject := parseSubject(s)
+// validateIdentity validates the certificates of the certificate header in the subject validation mode. Depending on
+// the SAN validation mode, a certificate is valid when its subject, its URI Subject Alternative Name, or both are valid.
+// It returns the name of the strategy which approved the identity.
+func (ph *proxyHandler) validateIdentity(certInfoData string, applicationClientIDs []string, applicationName string) (string, error) {
+	certificates := ph.extractCertificates(certInfoData)
+	if len(certificates) == 0 {
+		return "", errors.New("no subject found in the certificate header")
+	}
 
-		if subjectValidator(parsedSubject) {
-			return true
+	subjectStrategy := subjectValidationStrategy(ph.subjectValidator, applicationClientIDs)
+	if ph.subjectValidationMode == SubjectValidationModeAll {
+		strategy := ""
+		for _, certificate := range certificates {
+			certificateStrategy, err := ph.validateCertificate(certificate, applicationClientIDs, applicationName, subjectStrategy)
+			if err != nil {
+				return "", err
+			}
+			if strategy == "" {
+				strategy = certificateStrategy
+			}
 		}
+		return strategy, nil
 	}
 
-	return false
+	var firstErr error
+	for _, certificate := range certificates {
+		strategy, err := ph.validateCertificate(certificate, applicationClientIDs, applicationName, subjectStrategy)
+		if err == nil {
+			return strategy, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", firstErr
 }
 
-func newSubjectValidator(applicationClientIDs []string, appName string) func(subject pkix.Name) bool {
-	validateCommonNameWithAppName := func(subject pkix.Name) bool {
-		return appName == subject.CommonName
+// validateCertificate validates a single certificate of the header in the SAN validation mode
+func (ph *proxyHandler) validateCertificate(certificate clientCertificate, applicationClientIDs []string, applicationName, subjectStrategy string) (string, error) {
+	// the required attributes are a baseline, which the URI Subject Alternative Name does not replace
+	if err := ph.validateRequiredAttributes(certificate.subject); err != nil {
+		return "", err
 	}
-	validateCommonNameWithClientIDs := func(subject pkix.Name) bool {
-		for _, id := range applicationClientIDs {
-			if subject.CommonName == id {
-				return true
-			}
+
+	subjectErr := errNoCertificateSubject
+	if certificate.subject != nil {
+		subjectErr = ph.withSubjectAttributeName(ph.subjectValidator.Validate(certificate.subject.name, applicationName, applicationClientIDs))
+	}
+	if ph.sanValidationMode == SANValidationModeDisabled || ph.sanValidationMode == "" {
+		return subjectStrategy, subjectErr
+	}
+
+	sanErr := validateSANURIs(certificate.uris, ph.sanURIPrefix, applicationName)
+	return identityValidationStrategy(ph.sanValidationMode, subjectStrategy, subjectErr), combineIdentityValidation(ph.sanValidationMode, subjectErr, sanErr)
+}
+
+var errNoCertificateSubject = errors.New("no subject found in the certificate")
+
+// validateRequiredAttributes rejects the subject missing a required attribute, the missing subject has no attributes
+func (ph *proxyHandler) validateRequiredAttributes(subject *certificateSubject) error {
+	for _, attribute := range ph.requiredAttributes {
+		if subject == nil || subject.attributes[attribute] == "" {
+			return &SubjectMismatchError{Field: attribute, Expected: "non-empty value", Actual: ""}
 		}
-		return false
 	}
-	if len(applicationClientIDs) == 0 {
-		return validateCommonNameWithAppName
-	} else {
-		return validateCommonNameWithClientIDs
+	return nil
+}
+
+// withSubjectAttributeName reports the subject mismatch of CN, O, or OU with the name of the subject attribute
+// read into the field, so that the rejection names the attribute of the certificate instead of the field
+func (ph *proxyHandler) withSubjectAttributeName(err error) error {
+	var mismatchErr *SubjectMismatchError
+	if !errors.As(err, &mismatchErr) {
+		return err
+	}
+
+	attribute := map[string]string{
+		"CN": ph.subjectAttributes.Identity,
+		"O":  ph.subjectAttributes.Organization,
+		"OU": ph.subjectAttributes.OrganizationalUnit,
+	}[mismatchErr.Field]
+	if attribute == "" || attribute == mismatchErr.Field {
+		return err
 	}
+
+	renamed := *mismatchErr
+	renamed.Field = attribute
+	return &renamed
 }
 
-func (ph *proxyHandler) extractSubjects(certInfoData string) []string {
-	var subjects []string
+// subjectRejectionSummary describes the subject rejection for the client, only the mismatching field is disclosed
+func subjectRejectionSummary(err error) string {
+	var mismatchErr *SubjectMismatchError
+	if errors.As(err, &mismatchErr) {
+		return ": " + mismatchErr.Summary()
+	}
+	return ""
+}
 
-	subjectMatches := ph.subjectRegex.FindAllStringSubmatch(certInfoData, -1)
+// subjectRegex matches the subject of a certificate in the certificate header, it is shared by all handlers
+var subjectRegex = regexp.MustCompile(`Subject="(.*?)"`)
 
-	for _, subjectMatch := range subjectMatches {
-		subject := get(subjectMatch, 1)
+// certificateSubject is the parsed certificate subject with the attributes presented in the certificate header
+type certificateSubject struct {
+	name       pkix.Name
+	attributes map[string]string
+}
 
-		if subject != "" {
-			subjects = append(subjects, subject)
+// clientCertificate is a single certificate of the certificate header, its subject is nil when it presents none
+type clientCertificate struct {
+	subject *certificateSubject
+	uris    []string
+}
+
+// extractCertificates returns the certificates of the header presenting a subject, or a URI Subject Alternative Name
+// when it is validated
+func (ph *proxyHandler) extractCertificates(certInfoData string) []clientCertificate {
+	validateSAN := ph.sanValidationMode != SANValidationModeDisabled && ph.sanValidationMode != ""
+
+	var certificates []clientCertificate
+	for _, element := range splitCertificateElements(certInfoData) {
+		certificate := clientCertificate{subject: ph.extractSubject(element)}
+		if validateSAN {
+			certificate.uris = extractSANURIs(element)
+		}
+		if certificate.subject != nil || len(certificate.uris) > 0 {
+			certificates = append(certificates, certificate)
 		}
 	}
+	return certificates
+}
+
+func (ph *proxyHandler) extractSubject(element string) *certificateSubject {
+	subject := get(subjectRegex.FindStringSubmatch(element), 1)
+	if subject == "" {
+		return nil
+	}
 
-	return subjects
+	attributes := extractSubject(subject, ph.subjectDelimiter)
+	return &certificateSubject{
+		name:       ph.withSubjectDefaults(parseSubject(attributes, ph.subjectAttributes)),
+		attributes: attributes,
+	}
+}
+
+// splitCertificateElements splits the certificate header into the elements describing single certificates,
+// the commas of the quoted values do not separate the elements
+func splitCertificateElements(certInfoData string) []string {
+	var elements []string
+	quoted, escaped, start := false, false, 0
+	for i, c := range certInfoData {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && quoted:
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case c == ',' && !quoted:
+			elements = append(elements, certInfoData[start:i])
+			start = i + 1
+		}
+	}
+	return append(elements, certInfoData[start:])
+}
+
+// withSubjectDefaults fills the missing organization and organizational unit of the subject with the defaults
+func (ph *proxyHandler) withSubjectDefaults(subject pkix.Name) pkix.Name {
+	if ph.defaultOrganization != "" && isBlank(subject.Organization) {
+		subject.Organization = []string{ph.defaultOrganization}
+	}
+	if ph.defaultOrganizationalUnit != "" && isBlank(subject.OrganizationalUnit) {
+		subject.OrganizationalUnit = []string{ph.defaultOrganizationalUnit}
+	}
+	return subject
+}
+
+func isBlank(values []string) bool {
+	for _, value := range values {
+		if value != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(array []string, value string) bool {
+	for _, item := range array {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
 
 func get(array []string, index int) string {
@@ -219,33 +1097,34 @@ func get(array []string, index int) string {
 	return ""
 }
 
-func parseSubject(rawSubject string) pkix.Name {
-	subjectInfo := extractSubject(rawSubject)
-
+func parseSubject(subjectInfo map[string]string, attributes SubjectAttributes) pkix.Name {
 	return pkix.Name{
-		CommonName:         subjectInfo["CN"],
+		CommonName:         subjectInfo[attributes.Identity],
 		Country:            []string{subjectInfo["C"]},
-		Organization:       []string{subjectInfo["O"]},
-		OrganizationalUnit: []string{subjectInfo["OU"]},
+		Organization:       []string{subjectInfo[attributes.Organization]},
+		OrganizationalUnit: []string{subjectInfo[attributes.OrganizationalUnit]},
 		Locality:           []string{subjectInfo["L"]},
 		Province:           []string{subjectInfo["ST"]},
 	}
 }
 
-func extractSubject(subject string) map[string]string {
+func extractSubject(subject, delimiter string) map[string]string {
 	result := map[string]string{}
 
-	segments := strings.Split(subject, ",")
+	segments := strings.Split(subject, delimiter)
 
 	for _, segment := range segments {
-		parts := strings.Split(segment, "=")
-		result[parts[0]] = parts[1]
+		key, value, found := strings.Cut(segment, "=")
+		if !found {
+			continue
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(value)
 	}
 
 	return result
 }
 
-func createReverseProxy(log *logger.Logger, destinationHost string, reqOpts ...requestOption) *httputil.ReverseProxy {
+func createReverseProxy(log *logger.Logger, redactor *pathRedactor, destinationHost string, reqOpts ...requestOption) *httputil.ReverseProxy {
 
 	return &httputil.ReverseProxy{
 		Director: func(request *http.Request) {
@@ -254,16 +1133,40 @@ func createReverseProxy(log *logger.Logger, destinationHost string, reqOpts ...r
 				opt(request)
 			}
 
-			log.WithTracing(request.Context()).With("handler", handlerName).With("targetURL", request.URL).Infof("Proxying request to target URL...")
+			if isLogSampled(request.Context()) {
+				log.WithTracing(request.Context()).With("handler", handlerName).With("targetURL", redactor.redact(request.URL.String())).Infof("Proxying request to target URL...")
+			}
 		},
 		ModifyResponse: func(res *http.Response) error {
-			log.WithContext().With("handler", handlerName).Infof("Host responded with status %s", res.Status)
+			if isLogSampled(res.Request.Context()) || res.StatusCode >= 500 {
+				log.WithContext().With("handler", handlerName).Infof("Host responded with status %s", res.Status)
+			}
+			// the backpressure keeps its status, so that the clients honour the Retry-After instead of failing
+			if ensureBackpressureRetryAfter(log, res) {
+				return nil
+			}
 			if res.StatusCode >= 500 && res.StatusCode < 600 {
 				res.Header.Set("Target-System-Status", strconv.Itoa(res.StatusCode))
 				res.StatusCode = http.StatusBadGateway
 			}
 			return nil
 		},
+		ErrorHandler: func(w http.ResponseWriter, request *http.Request, err error) {
+			if errors.Is(err, context.Canceled) {
+				log.WithTracing(request.Context()).With("handler", handlerName).With("targetURL", redactor.redact(request.URL.String())).Infof("Request cancelled by the client, upstream call aborted")
+				return
+			}
+
+			errorLog := log.WithTracing(request.Context()).With("handler", handlerName).With("targetURL", redactor.redact(request.URL.String())).With("reason", err.Error())
+
+			var netErr net.Error
+			if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+				httptools.RespondWithNegotiatedError(errorLog, w, request, apperrors.GatewayTimeout("proxying request to target URL timed out").WithErrorCode(ErrorCodeUpstreamTimeout))
+				return
+			}
+
+			httptools.RespondWithNegotiatedError(errorLog, w, request, apperrors.BadGateway("proxying request to target URL failed").WithErrorCode(ErrorCodeUpstreamUnreachable))
+		},
 		Transport: &http.Transport{
 			DialContext: (&net.Dialer{
 				Timeout:   30 * time.Second,
@@ -281,8 +1184,98 @@ func createReverseProxy(log *logger.Logger, destinationHost string, reqOpts ...r
 	}
 }
 
+// ensureBackpressureRetryAfter sets the default Retry-After header on the destination responses signalling backpressure,
+// it reports whether the response signals backpressure
+func ensureBackpressureRetryAfter(log *logger.Logger, res *http.Response) bool {
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+
+	retryAfter := res.Header.Get("Retry-After")
+	if retryAfter == "" {
+		retryAfter = retryAfterSeconds
+		res.Header.Set("Retry-After", retryAfter)
+	}
+
+	log.WithTracing(res.Request.Context()).With("handler", handlerName).With("applicationName", mux.Vars(res.Request)["application"]).With("retryAfter", retryAfter).Warnf("Host signalled backpressure with status %s", res.Status)
+	return true
+}
+
 type requestOption func(req *http.Request)
 
+// appendRequestOptions runs the request options after the current Director of the proxy
+func appendRequestOptions(proxy *httputil.ReverseProxy, reqOpts ...requestOption) {
+	director := proxy.Director
+	proxy.Director = func(request *http.Request) {
+		director(request)
+		for _, opt := range reqOpts {
+			opt(request)
+		}
+	}
+}
+
+type responseOption func(res *http.Response)
+
+// appendResponseOptions runs the response options after the current ModifyResponse of the proxy
+func appendResponseOptions(proxy *httputil.ReverseProxy, resOpts ...responseOption) {
+	modifyResponse := proxy.ModifyResponse
+	proxy.ModifyResponse = func(res *http.Response) error {
+		if modifyResponse != nil {
+			if err := modifyResponse(res); err != nil {
+				return err
+			}
+		}
+		for _, opt := range resOpts {
+			opt(res)
+		}
+		return nil
+	}
+}
+
+// withRewrittenInternalLocation rewrites the Location header pointing at the destination host or at a cluster-local service
+func withRewrittenInternalLocation(destinationHost, publicPathPrefix string) responseOption {
+	return func(res *http.Response) {
+		location := res.Header.Get("Location")
+		if location == "" {
+			return
+		}
+
+		locationURL, err := url.Parse(location)
+		if err != nil {
+			res.Header.Del("Location")
+			return
+		}
+		if !isInternalHost(locationURL.Host, destinationHost) {
+			return
+		}
+
+		if publicPathPrefix == "" {
+			res.Header.Del("Location")
+			return
+		}
+		rewritten := url.URL{
+			Path:     strings.TrimSuffix(publicPathPrefix, "/") + "/" + strings.TrimPrefix(locationURL.Path, "/"),
+			RawQuery: locationURL.RawQuery,
+		}
+		res.Header.Set("Location", rewritten.String())
+	}
+}
+
+func isInternalHost(host, destinationHost string) bool {
+	if host == "" {
+		return false
+	}
+	if host == destinationHost {
+		return true
+	}
+
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	return strings.HasSuffix(hostname, ".svc") || strings.HasSuffix(hostname, ".svc.cluster.local")
+}
+
 // withRewriteBaseURL rewrites the Request's Path.
 func withRewriteBaseURL(path string) requestOption {
 	return func(req *http.Request) {
@@ -296,6 +1289,16 @@ func withEmptyRequestHost(req *http.Request) {
 	req.Host = ""
 }
 
+// preserveRequestHost restores the Host of the request cleared by the current Director of the proxy
+func preserveRequestHost(proxy *httputil.ReverseProxy) {
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		host := req.Host
+		director(req)
+		req.Host = host
+	}
+}
+
 // withHTTPScheme sets the URL scheme to HTTP
 func withHTTPScheme(req *http.Request) {
 	req.URL.Scheme = "http"
@@ -305,3 +1308,27 @@ func withHTTPScheme(req *http.Request) {
 func withEmptyXFwdClientCert(req *http.Request) {
 	req.Header.Del("X-Forwarded-Client-Cert")
 }
+
+// withTrustedXFwdFor keeps the given number of the last X-Forwarded-For entries
+func withTrustedXFwdFor(hops int) requestOption {
+	return func(req *http.Request) {
+		var entries []string
+		for _, value := range req.Header.Values(xForwardedForHeader) {
+			for _, entry := range strings.Split(value, ",") {
+				if entry = strings.TrimSpace(entry); entry != "" {
+					entries = append(entries, entry)
+				}
+			}
+		}
+
+		if len(entries) > hops {
+			entries = entries[len(entries)-hops:]
+		}
+
+		if len(entries) == 0 {
+			req.Header.Del(xForwardedForHeader)
+			return
+		}
+		req.Header.Set(xForwardedForHeader, strings.Join(entries, ", "))
+	}
+}
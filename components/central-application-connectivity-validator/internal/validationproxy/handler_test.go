@@ -1,13 +1,24 @@
 package validationproxy
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httpconsts"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httperrors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,8 +26,11 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -75,6 +89,13 @@ var (
 	}
 )
 
+// setCertificateInfoHeader sets the certificate header, an empty value leaves the header absent
+func setCertificateInfoHeader(req *http.Request, certInfoHeader string) {
+	if certInfoHeader != "" {
+		req.Header.Set(CertificateInfoHeader, certInfoHeader)
+	}
+}
+
 func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 	log, err := logger.New(logger.TEXT, logger.ERROR)
@@ -204,11 +225,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 			idCache.Set(testCase.application.Name, appData, cache.NoExpiration)
 
-			proxyHandler := NewProxyHandler(
-				eventPublisherProxyHost,
-				eventingDestinationPathPublish,
-				idCache,
-				log)
+			proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+				EventingPublisherHost:   eventPublisherProxyHost,
+				EventingDestinationPath: eventingDestinationPathPublish,
+			}, idCache, log)
+			require.NoError(t, err)
 
 			t.Run("should proxy eventing V1 request when "+testCase.caseDescription, func(t *testing.T) {
 				eventTitle := "my-event-1"
@@ -231,7 +252,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/v1/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				recorder := httptest.NewRecorder()
@@ -249,7 +270,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				recorder := httptest.NewRecorder()
@@ -268,7 +289,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				// mock request Host to assert it gets rewritten by the proxy
@@ -321,11 +342,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 		idCache.Set(application.Name, appData, cache.NoExpiration)
 
-		proxyHandler := NewProxyHandler(
-			eventPublisherProxyHost,
-			eventingDestinationPathPublish,
-			idCache,
-			log)
+		proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   eventPublisherProxyHost,
+			EventingDestinationPath: eventingDestinationPathPublish,
+		}, idCache, log)
+		require.NoError(t, err)
 
 		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", application.Name), nil)
 		require.NoError(t, err)
@@ -350,15 +371,15 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 			// given
 			idCache := cache.New(time.Minute, time.Minute)
 
-			proxyHandler := NewProxyHandler(
-				eventingPublisherHost,
-				eventingDestinationPathPublish,
-				idCache,
-				log)
+			proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+				EventingPublisherHost:   eventingPublisherHost,
+				EventingDestinationPath: eventingDestinationPathPublish,
+			}, idCache, log)
+			require.NoError(t, err)
 
 			req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/%s/v1/metadata/services", testCase.application.Name), nil)
 			require.NoError(t, err)
-			req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+			setCertificateInfoHeader(req, testCase.certInfoHeader)
 			req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 			recorder := httptest.NewRecorder()
 
@@ -389,11 +410,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 		idCache := cache.New(time.Minute, time.Minute)
 		idCache.Set(applicationName, appData, cache.NoExpiration)
 
-		proxyHandler := NewProxyHandler(
-			eventingPublisherHost,
-			eventingDestinationPathPublish,
-			idCache,
-			log)
+		proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   eventingPublisherHost,
+			EventingDestinationPath: eventingDestinationPathPublish,
+		}, idCache, log)
+		require.NoError(t, err)
 
 		req, err := http.NewRequest(http.MethodGet, "/path", nil)
 		require.NoError(t, err)
@@ -431,11 +452,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 		idCache.Set(applicationName, appData, cache.NoExpiration)
 
-		proxyHandler := NewProxyHandler(
-			eventingPublisherHost,
-			eventingDestinationPathPublish,
-			idCache,
-			log)
+		proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   eventingPublisherHost,
+			EventingDestinationPath: eventingDestinationPathPublish,
+		}, idCache, log)
+		require.NoError(t, err)
 
 		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/%s/v1/bad/path", applicationMetaName), nil)
 		require.NoError(t, err)
@@ -475,11 +496,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 			t.Run("should proxy requests in V1 to V1 endpoint of EPP when "+testCase.caseDescription, func(t *testing.T) {
 
-				proxyHandlerBEB := NewProxyHandler(
-					eventingPublisherHost,
-					eventingDestinationPathPublish,
-					idCache,
-					log)
+				proxyHandlerBEB, err := NewProxyHandlerFromConfig(ProxyConfig{
+					EventingPublisherHost:   eventingPublisherHost,
+					EventingDestinationPath: eventingDestinationPathPublish,
+				}, idCache, log)
+				require.NoError(t, err)
 				eventTitle := "my-event-1"
 
 				eventPublisherProxyHandler.PathPrefix("/{application}/v1/events").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -500,7 +521,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/v1/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				recorder := httptest.NewRecorder()
@@ -519,11 +540,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 				eventPublisherProxyServer := httptest.NewServer(eventPublisherProxyHandler)
 				eventPublisherProxyHost := strings.TrimPrefix(eventPublisherProxyServer.URL, "http://")
 
-				proxyHandlerBEB := NewProxyHandler(
-					eventPublisherProxyHost, // For a BEB enabled cluster requests to /v2 and /events should be forwarded to Event Publisher Proxy
-					eventingDestinationPathPublish,
-					idCache,
-					log)
+				proxyHandlerBEB, err := NewProxyHandlerFromConfig(ProxyConfig{
+					EventingPublisherHost:   eventPublisherProxyHost, // For a BEB enabled cluster requests to /v2 and /events should be forwarded to Event Publisher Proxy
+					EventingDestinationPath: eventingDestinationPathPublish,
+				}, idCache, log)
+				require.NoError(t, err)
 
 				eventPublisherProxyHandler.PathPrefix("/publish").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					var receivedEvent event
@@ -540,7 +561,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				recorder := httptest.NewRecorder()
@@ -559,11 +580,11 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 				eventPublisherProxyHandler := mux.NewRouter()
 				eventPublisherProxyServer := httptest.NewServer(eventPublisherProxyHandler)
 				eventPublisherProxyHost := strings.TrimPrefix(eventPublisherProxyServer.URL, "http://")
-				proxyHandlerBEB := NewProxyHandler(
-					eventPublisherProxyHost, // For a BEB enabled cluster requests to /v2 and /events should be forwarded to Event Publisher Proxy
-					eventingDestinationPathPublish,
-					idCache,
-					log)
+				proxyHandlerBEB, err := NewProxyHandlerFromConfig(ProxyConfig{
+					EventingPublisherHost:   eventPublisherProxyHost, // For a BEB enabled cluster requests to /v2 and /events should be forwarded to Event Publisher Proxy
+					EventingDestinationPath: eventingDestinationPathPublish,
+				}, idCache, log)
+				require.NoError(t, err)
 
 				eventPublisherProxyHandler.Path("/publish").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 					var receivedEvent event
@@ -582,7 +603,7 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 
 				req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/events", testCase.application.Name), bytes.NewReader(body))
 				require.NoError(t, err)
-				req.Header.Set(CertificateInfoHeader, testCase.certInfoHeader)
+				setCertificateInfoHeader(req, testCase.certInfoHeader)
 				req = mux.SetURLVars(req, map[string]string{"application": testCase.application.Name})
 
 				// mock request Host to assert it gets rewritten by the proxy
@@ -599,3 +620,2718 @@ func TestProxyHandler_ProxyAppConnectorRequests(t *testing.T) {
 		}
 	})
 }
+
+func TestProxyHandler_SubjectValidationMode(t *testing.T) {
+	const (
+		validSubject   = `Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE"`
+		invalidSubject = `Subject="CN=forged-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE"`
+	)
+
+	testCases := []struct {
+		caseDescription string
+		mode            SubjectValidationMode
+		certInfoHeader  string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "any mode with valid subject only",
+			mode:            SubjectValidationModeAny,
+			certInfoHeader:  "Hash=1;" + validSubject + ";URI=",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "any mode with valid and invalid subjects",
+			mode:            SubjectValidationModeAny,
+			certInfoHeader:  "Hash=1;" + validSubject + ";URI=,Hash=2;" + invalidSubject + ";URI=",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "any mode with invalid subject only",
+			mode:            SubjectValidationModeAny,
+			certInfoHeader:  "Hash=1;" + invalidSubject + ";URI=",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "all mode with valid subjects only",
+			mode:            SubjectValidationModeAll,
+			certInfoHeader:  "Hash=1;" + validSubject + ";URI=,Hash=2;" + validSubject + `;URI=,Hash=3;Subject="";URI=`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "all mode with valid and invalid subjects",
+			mode:            SubjectValidationModeAll,
+			certInfoHeader:  "Hash=1;" + validSubject + ";URI=,Hash=2;" + invalidSubject + ";URI=",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "all mode without subjects",
+			mode:            SubjectValidationModeAll,
+			certInfoHeader:  `Hash=1;Subject="";URI=`,
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should validate subjects in "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithSubjectValidationMode(testCase.mode))
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), testCase.certInfoHeader)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+type staticClientIDSource map[string][]string
+
+func (s staticClientIDSource) GetClientIDs(_ context.Context, applicationName string) ([]string, error) {
+	return s[applicationName], nil
+}
+
+func TestProxyHandler_ClientIDSource(t *testing.T) {
+	clientIDSource := staticClientIDSource{
+		applicationName: {"configmap-client-id"},
+	}
+
+	testCases := []struct {
+		caseDescription string
+		application     *appconnv1alpha1.Application
+		source          ClientIDSource
+		commonName      string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "application with Compass client IDs ignores the source",
+			application:     applicationWithClientIDs(applicationID),
+			source:          clientIDSource,
+			commonName:      applicationID,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "application with Compass client IDs rejects client ID from the source",
+			application:     applicationWithClientIDs(applicationID),
+			source:          clientIDSource,
+			commonName:      "configmap-client-id",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "application without Compass client IDs uses client ID from the source",
+			application:     applicationNotManagedByCompass,
+			source:          clientIDSource,
+			commonName:      "configmap-client-id",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "application without Compass client IDs rejects application name when the source has client IDs",
+			application:     applicationNotManagedByCompass,
+			source:          clientIDSource,
+			commonName:      applicationName,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "application without Compass client IDs falls back to application name when the source has no entry",
+			application:     applicationNotManagedByCompass,
+			source:          staticClientIDSource{},
+			commonName:      applicationName,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "application without Compass client IDs and without the source validates application name",
+			application:     applicationNotManagedByCompass,
+			commonName:      applicationName,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "application with Compass metadata without client IDs uses client ID from the source",
+			application:     applicationWithClientIDs(),
+			source:          clientIDSource,
+			commonName:      "configmap-client-id",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "application with Compass metadata without client IDs rejects application name when the source has client IDs",
+			application:     applicationWithClientIDs(),
+			source:          clientIDSource,
+			commonName:      applicationName,
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should validate subject when "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			var ops []Option
+			if testCase.source != nil {
+				ops = append(ops, WithClientIDSource(testCase.source))
+			}
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{testCase.application}, ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, testCase.commonName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_ClientCancellation(t *testing.T) {
+	t.Run("should abort upstream call when client cancels the request", func(t *testing.T) {
+		// given
+		upstreamReached := make(chan struct{})
+		upstreamAborted := make(chan struct{})
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+		h.setUpstreamHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(upstreamReached)
+			<-r.Context().Done()
+			close(upstreamAborted)
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		req := h.newRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), "").WithContext(ctx)
+		proxyDone := make(chan struct{})
+
+		// when
+		go func() {
+			defer close(proxyDone)
+			if res, err := http.DefaultClient.Do(req); err == nil {
+				res.Body.Close()
+			}
+		}()
+
+		<-upstreamReached
+		cancel()
+
+		// then
+		select {
+		case <-upstreamAborted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("upstream call was not aborted after client cancellation")
+		}
+
+		select {
+		case <-proxyDone:
+		case <-time.After(5 * time.Second):
+			t.Fatal("proxy did not return after client cancellation")
+		}
+	})
+}
+
+type commonNameRegexValidator struct {
+	regex *regexp.Regexp
+}
+
+func (v commonNameRegexValidator) Validate(subject pkix.Name, _ string, _ []string) error {
+	if !v.regex.MatchString(subject.CommonName) {
+		return errors.New("Common Name does not match the regex")
+	}
+	return nil
+}
+
+func TestProxyHandler_CustomSubjectValidator(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationWithClientIDs(applicationID)},
+		WithSubjectValidator(commonNameRegexValidator{regex: regexp.MustCompile(`^tenant-[a-z]+$`)}))
+
+	testCases := []struct {
+		caseDescription string
+		commonName      string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "Common Name matches the regex",
+			commonName:      "tenant-abc",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "Common Name is a client ID not matching the regex",
+			commonName:      applicationID,
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should use custom validator when "+testCase.caseDescription, func(t *testing.T) {
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, testCase.commonName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_DefaultSubjectOrganization(t *testing.T) {
+	validator := WithSubjectValidator(NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{Organization: "Organization", OrganizationalUnit: "OrgUnit"}))
+
+	testCases := []struct {
+		caseDescription string
+		ops             []Option
+		subject         string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "accept subject with organization without defaults",
+			ops:             []Option{validator},
+			subject:         "CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject subject without organization without defaults",
+			ops:             []Option{validator},
+			subject:         "CN=test-application,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "accept subject without organization with defaults",
+			ops:             []Option{validator, WithDefaultSubjectOrganization("Organization", "OrgUnit")},
+			subject:         "CN=test-application,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept subject with organization with defaults",
+			ops:             []Option{validator, WithDefaultSubjectOrganization("Organization", "OrgUnit")},
+			subject:         "CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "keep presented organization instead of the default",
+			ops:             []Option{validator, WithDefaultSubjectOrganization("Organization", "OrgUnit")},
+			subject:         "CN=test-application,OU=OrgUnit,O=Other,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject subject without organizational unit when only organization has default",
+			ops:             []Option{validator, WithDefaultSubjectOrganization("Organization", "")},
+			subject:         "CN=test-application,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(`Hash=1;Subject="%s";URI=`, testCase.subject))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_SubjectAttributes(t *testing.T) {
+	identityInOU := WithSubjectAttributes(SubjectAttributes{Identity: "OU", OrganizationalUnit: "CN"})
+
+	testCases := []struct {
+		caseDescription string
+		ops             []Option
+		subject         string
+		expectedStatus  int
+		expectedField   string
+	}{
+		{
+			caseDescription: "accept subject with identity in OU",
+			ops:             []Option{identityInOU},
+			subject:         "CN=OrgUnit,OU=test-application,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject subject with identity only in CN when identity is in OU",
+			ops:             []Option{identityInOU},
+			subject:         "CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+			expectedField:   "OU",
+		},
+		{
+			caseDescription: "reject subject with identity in OU by default",
+			subject:         "CN=OrgUnit,OU=test-application,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+			expectedField:   "CN",
+		},
+		{
+			caseDescription: "validate organizational unit read from the mapped attribute",
+			ops:             []Option{identityInOU, WithSubjectValidator(NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{Organization: "Organization", OrganizationalUnit: "OrgUnit"}))},
+			subject:         "CN=OrgUnit,OU=test-application,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject mismatched group read from the mapped attribute",
+			ops:             []Option{identityInOU, WithSubjectValidator(NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{Organization: "Organization", OrganizationalUnit: "OrgUnit"}))},
+			subject:         "CN=OtherUnit,OU=test-application,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusForbidden,
+			expectedField:   "CN",
+		},
+		{
+			caseDescription: "validate tenant read from serialNumber",
+			ops: []Option{
+				WithSubjectAttributes(SubjectAttributes{Organization: "serialNumber"}),
+				WithSubjectValidator(NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{Organization: "tenant-1", OrganizationalUnit: "OrgUnit"})),
+			},
+			subject:        "CN=test-application,OU=OrgUnit,O=Organization,serialNumber=tenant-1,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			caseDescription: "reject mismatched tenant read from serialNumber",
+			ops: []Option{
+				WithSubjectAttributes(SubjectAttributes{Organization: "serialNumber"}),
+				WithSubjectValidator(NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{Organization: "tenant-1", OrganizationalUnit: "OrgUnit"})),
+			},
+			subject:        "CN=test-application,OU=OrgUnit,O=tenant-1,serialNumber=tenant-2,L=Waldorf,ST=Waldorf,C=DE",
+			expectedStatus: http.StatusForbidden,
+			expectedField:  "serialNumber",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(`Hash=1;Subject="%s";URI=`, testCase.subject))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedField != "" {
+				expectedError := fmt.Sprintf("no valid subject found: subject field %s does not match the application", testCase.expectedField)
+				assert.Equal(t, expectedError, h.errorResponse(res).Error)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_RequiredSubjectAttributes(t *testing.T) {
+	required := WithRequiredSubjectAttributes("O", "OU")
+
+	testCases := []struct {
+		caseDescription string
+		ops             []Option
+		certInfo        string
+		expectedStatus  int
+		expectedReason  string
+	}{
+		{
+			caseDescription: "accept subject with all required attributes",
+			ops:             []Option{required},
+			certInfo:        `Hash=1;Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject subject missing required attribute",
+			ops:             []Option{required},
+			certInfo:        `Hash=1;Subject="CN=test-application,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus:  http.StatusForbidden,
+			expectedReason:  "subject field OU does not match the application",
+		},
+		{
+			caseDescription: "reject subject with empty required attribute",
+			ops:             []Option{required},
+			certInfo:        `Hash=1;Subject="CN=test-application,OU=,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus:  http.StatusForbidden,
+			expectedReason:  "subject field OU does not match the application",
+		},
+		{
+			caseDescription: "reject subject missing required attribute with default organization",
+			ops:             []Option{required, WithDefaultSubjectOrganization("Organization", "OrgUnit")},
+			certInfo:        `Hash=1;Subject="CN=test-application,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus:  http.StatusForbidden,
+			expectedReason:  "subject field O does not match the application",
+		},
+		{
+			caseDescription: "accept subject missing attribute without requirement",
+			certInfo:        `Hash=1;Subject="CN=test-application,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept second subject with all required attributes in any mode",
+			ops:             []Option{required},
+			certInfo: `Hash=1;Subject="CN=test-application,L=Waldorf,ST=Waldorf,C=DE";URI=,` +
+				`Hash=2;Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			caseDescription: "reject subject missing required attribute in all mode",
+			ops:             []Option{required, WithSubjectValidationMode(SubjectValidationModeAll)},
+			certInfo: `Hash=1;Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=,` +
+				`Hash=2;Subject="CN=test-application,OU=OrgUnit,L=Waldorf,ST=Waldorf,C=DE";URI=`,
+			expectedStatus: http.StatusForbidden,
+			expectedReason: "subject field O does not match the application",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), testCase.certInfo)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			body, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), testCase.expectedReason)
+		})
+	}
+}
+
+func BenchmarkExtractCertificates(b *testing.B) {
+	log, err := logger.New(logger.TEXT, logger.ERROR)
+	require.NoError(b, err)
+	handler, err := NewProxyHandlerFromConfig(ProxyConfig{
+		EventingPublisherHost:   "eventing-publisher:8080",
+		EventingDestinationPath: eventingDestinationPathPublish,
+	}, cache.New(time.Minute, time.Minute), log)
+	require.NoError(b, err)
+	proxyHandler := handler.(*proxyHandler)
+
+	certInfo := `By=spiffe://cluster.local/ns/kyma-system/sa/default;Hash=1;Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=,` +
+		`By=spiffe://cluster.local/ns/kyma-system/sa/default;Hash=2;Subject="CN=test-application-2,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if certificates := proxyHandler.extractCertificates(certInfo); len(certificates) != 2 {
+			b.Fatalf("expected 2 certificates, got %d", len(certificates))
+		}
+	}
+}
+
+type failingClientIDSource struct{}
+
+func (failingClientIDSource) GetClientIDs(_ context.Context, _ string) ([]string, error) {
+	return nil, fmt.Errorf("source unavailable")
+}
+
+func TestProxyHandler_ErrorCodes(t *testing.T) {
+	validCertInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	testCases := []struct {
+		caseDescription   string
+		path              string
+		certInfoHeader    string
+		applications      []*appconnv1alpha1.Application
+		ops               []Option
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription:   "certificate header is missing",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			applications:      []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeCertificateHeaderNotFound,
+		},
+		{
+			caseDescription:   "certificate header is present but empty",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			certInfoHeader:    " ",
+			applications:      []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			expectedStatus:    http.StatusUnauthorized,
+			expectedErrorCode: ErrorCodeCertificateHeaderEmpty,
+		},
+		{
+			caseDescription:   "application is not found in the cache",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			certInfoHeader:    validCertInfoHeader,
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeAppNotFound,
+		},
+		{
+			caseDescription:   "client IDs source fails",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			certInfoHeader:    validCertInfoHeader,
+			applications:      []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			ops:               []Option{WithClientIDSource(failingClientIDSource{})},
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeClientIDsUnavailable,
+		},
+		{
+			caseDescription:   "no valid subject is found",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			certInfoHeader:    fmt.Sprintf(harnessCertInfoHeaderValue, "invalid-cn"),
+			applications:      []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			expectedStatus:    http.StatusForbidden,
+			expectedErrorCode: ErrorCodeForbiddenNoSubject,
+		},
+		{
+			caseDescription:   "destination is not found",
+			path:              fmt.Sprintf("/%s/v1/bad/path", applicationName),
+			certInfoHeader:    validCertInfoHeader,
+			applications:      []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeDestinationNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should return error code when "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, testCase.applications, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, testCase.path, testCase.certInfoHeader)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+
+			errorResponse := h.errorResponse(res)
+			assert.Equal(t, testCase.expectedStatus, errorResponse.Code)
+			assert.Equal(t, testCase.expectedErrorCode, errorResponse.ErrorCode)
+			assert.NotEmpty(t, errorResponse.Error)
+		})
+	}
+
+	t.Run("should return error code when application name is not specified", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+
+		// the router does not route the requests without the application, so the handler is called directly
+		req, err := http.NewRequest(http.MethodPost, "/path", nil)
+		require.NoError(t, err)
+		req.Header.Set(CertificateInfoHeader, validCertInfoHeader)
+		recorder := httptest.NewRecorder()
+
+		// when
+		h.handler.ProxyAppConnectorRequests(recorder, req)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var errorResponse httperrors.ErrorResponse
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&errorResponse))
+		assert.Equal(t, http.StatusBadRequest, errorResponse.Code)
+		assert.Equal(t, ErrorCodeAppNameNotSpecified, errorResponse.ErrorCode)
+		assert.NotEmpty(t, errorResponse.Error)
+	})
+}
+
+// loggedContextField returns the field logged in the "context" namespace of the logger
+func loggedContextField(entry observer.LoggedEntry, key string) interface{} {
+	fields, ok := entry.ContextMap()["context"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return fields[key]
+}
+
+func TestProxyHandler_PathRedaction(t *testing.T) {
+	t.Run("should redact configured path segments in the logs", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithPathRedaction(regexp.MustCompile(`secret-[a-z0-9]+`)))
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events/secret-1234/details", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		entryLogs := h.logs.FilterMessage("Proxying request for application...").All()
+		require.Len(t, entryLogs, 1)
+		assert.Equal(t, fmt.Sprintf("/%s/v1/events/***/details", applicationName), loggedContextField(entryLogs[0], "proxyPath"))
+
+		directorLogs := h.logs.FilterMessage("Proxying request to target URL...").All()
+		require.Len(t, directorLogs, 1)
+		targetURL := loggedContextField(directorLogs[0], "targetURL")
+		assert.Equal(t, fmt.Sprintf("%s/%s/v1/events/***/details", h.upstream.URL, applicationName), targetURL)
+		assert.NotContains(t, targetURL, "secret-1234")
+	})
+}
+
+func TestProxyHandler_ResponseHeaderTimeout(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithResponseHeaderTimeout(DestinationCloudEvents, 20*time.Millisecond))
+	h.setUpstreamHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	testCases := []struct {
+		caseDescription   string
+		path              string
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription:   "return 504 when slow cloud events destination exceeds the timeout",
+			path:              fmt.Sprintf("/%s/v2/events", applicationName),
+			expectedStatus:    http.StatusGatewayTimeout,
+			expectedErrorCode: ErrorCodeUpstreamTimeout,
+		},
+		{
+			caseDescription: "proxy request to slow legacy events destination without timeout",
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// when
+			res := h.do(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				errorResponse := h.errorResponse(res)
+				assert.Equal(t, testCase.expectedErrorCode, errorResponse.ErrorCode)
+				assert.Equal(t, "proxying request to target URL timed out", errorResponse.Error)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_UpstreamUnreachable(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+	h.upstream.Close()
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	t.Run("should return 502 with the error code when the destination is unreachable", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+		errorResponse := h.errorResponse(res)
+		assert.Equal(t, ErrorCodeUpstreamUnreachable, errorResponse.ErrorCode)
+		assert.Equal(t, "proxying request to target URL failed", errorResponse.Error)
+	})
+
+	t.Run("should return the plain text error when preferred by the client", func(t *testing.T) {
+		// given
+		req := h.newRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader, "")
+		req.Header.Set("Accept", "text/plain")
+
+		// when
+		res := h.send(req)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, ErrorCodeUpstreamUnreachable+": proxying request to target URL failed\n", string(body))
+	})
+}
+
+func TestProxyHandler_DisabledDestinations(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithDisabledDestinations(DestinationLegacyEvents))
+
+	testCases := []struct {
+		caseDescription string
+		path            string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "return 404 for disabled legacy events destination",
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			caseDescription: "proxy V2 request to enabled cloud events destination",
+			path:            fmt.Sprintf("/%s/v2/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "proxy request to enabled cloud events destination",
+			path:            fmt.Sprintf("/%s/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// when
+			res := h.do(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_AllowedMethods(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithAllowedMethods(DestinationLegacyEvents, http.MethodPost),
+		WithAllowedMethods(DestinationCloudEvents, http.MethodPost, http.MethodPut))
+
+	testCases := []struct {
+		caseDescription string
+		method          string
+		path            string
+		expectedStatus  int
+		expectedAllow   string
+	}{
+		{
+			caseDescription: "proxy allowed method to legacy events destination",
+			method:          http.MethodPost,
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "return 405 for disallowed method to legacy events destination",
+			method:          http.MethodGet,
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:  http.StatusMethodNotAllowed,
+			expectedAllow:   "POST",
+		},
+		{
+			caseDescription: "proxy allowed method to cloud events destination",
+			method:          http.MethodPut,
+			path:            fmt.Sprintf("/%s/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "return 405 for disallowed method to cloud events destination",
+			method:          http.MethodDelete,
+			path:            fmt.Sprintf("/%s/v2/events", applicationName),
+			expectedStatus:  http.StatusMethodNotAllowed,
+			expectedAllow:   "POST, PUT",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// when
+			res := h.do(testCase.method, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			assert.Equal(t, testCase.expectedAllow, res.Header.Get("Allow"))
+		})
+	}
+}
+
+func TestProxyHandler_XForwardedFor(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		ops             []Option
+		incomingXFwdFor []string
+		expectedXFwdFor string
+	}{
+		{
+			caseDescription: "append client address when header is missing",
+			expectedXFwdFor: "127.0.0.1",
+		},
+		{
+			caseDescription: "chain client address to existing entries",
+			incomingXFwdFor: []string{"1.1.1.1, 2.2.2.2"},
+			expectedXFwdFor: "1.1.1.1, 2.2.2.2, 127.0.0.1",
+		},
+		{
+			caseDescription: "drop spoofed entries not appended by trusted proxies",
+			ops:             []Option{WithTrustedProxyHops(1)},
+			incomingXFwdFor: []string{"1.1.1.1", "2.2.2.2"},
+			expectedXFwdFor: "2.2.2.2, 127.0.0.1",
+		},
+		{
+			caseDescription: "keep entries when there are fewer than trusted proxies",
+			ops:             []Option{WithTrustedProxyHops(2)},
+			incomingXFwdFor: []string{"2.2.2.2"},
+			expectedXFwdFor: "2.2.2.2, 127.0.0.1",
+		},
+		{
+			caseDescription: "drop all entries without trusted proxies",
+			ops:             []Option{WithTrustedProxyHops(0)},
+			incomingXFwdFor: []string{"1.1.1.1, 2.2.2.2"},
+			expectedXFwdFor: "127.0.0.1",
+		},
+		{
+			caseDescription: "keep all entries for -1 trusted proxies",
+			ops:             []Option{WithTrustedProxyHops(-1)},
+			incomingXFwdFor: []string{"1.1.1.1, 2.2.2.2"},
+			expectedXFwdFor: "1.1.1.1, 2.2.2.2, 127.0.0.1",
+		},
+		{
+			caseDescription: "keep all entries for negative trusted proxies",
+			ops:             []Option{WithTrustedProxyHops(-5)},
+			incomingXFwdFor: []string{"1.1.1.1", "2.2.2.2"},
+			expectedXFwdFor: "1.1.1.1, 2.2.2.2, 127.0.0.1",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			req := h.newRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), "")
+			for _, value := range testCase.incomingXFwdFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			// when
+			res := h.send(req)
+
+			// then
+			require.Equal(t, http.StatusOK, res.StatusCode)
+			require.Len(t, h.receivedRequests(), 1)
+			assert.Equal(t, testCase.expectedXFwdFor, h.receivedRequests()[0].Header.Get("X-Forwarded-For"))
+		})
+	}
+}
+
+func TestProxyHandler_ClientIDCacheMetrics(t *testing.T) {
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	t.Run("should count cache miss and hit for the same application", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, nil)
+
+		hits := testutil.ToFloat64(clientIDCacheHits)
+		misses := testutil.ToFloat64(clientIDCacheMisses)
+
+		// when
+		miss := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		h.appCache.Set(applicationName, controller.CachedAppData{
+			ClientIDs:           []string{},
+			AppPathPrefixV1:     fmt.Sprintf("/%s/v1/events", applicationName),
+			AppPathPrefixV2:     fmt.Sprintf("/%s/v2/events", applicationName),
+			AppPathPrefixEvents: fmt.Sprintf("/%s/events", applicationName),
+		}, cache.NoExpiration)
+
+		hit := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, miss.StatusCode)
+		assert.Equal(t, http.StatusOK, hit.StatusCode)
+		assert.Equal(t, misses+1, testutil.ToFloat64(clientIDCacheMisses))
+		assert.Equal(t, hits+1, testutil.ToFloat64(clientIDCacheHits))
+	})
+
+	t.Run("should count fetch error of the fallback client ID source", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithClientIDSource(failingClientIDSource{}))
+
+		fetchErrors := testutil.ToFloat64(clientIDFetchErrors)
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+		assert.Equal(t, fetchErrors+1, testutil.ToFloat64(clientIDFetchErrors))
+	})
+}
+
+func TestProxyHandler_SubjectDelimiter(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		subject         string
+		ops             []Option
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "accept semicolon-delimited subject with semicolon delimiter",
+			subject:         "CN=test-application;OU=OrgUnit;O=Organization;L=Waldorf;ST=Waldorf;C=DE",
+			ops:             []Option{WithSubjectDelimiter(";")},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject semicolon-delimited subject with default delimiter",
+			subject:         "CN=test-application;OU=OrgUnit;O=Organization;L=Waldorf;ST=Waldorf;C=DE",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "accept comma-delimited subject with empty delimiter",
+			subject:         "CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE",
+			ops:             []Option{WithSubjectDelimiter("")},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "keep the delimiter set before the empty delimiter",
+			subject:         "CN=test-application;OU=OrgUnit;O=Organization;L=Waldorf;ST=Waldorf;C=DE",
+			ops:             []Option{WithSubjectDelimiter(";"), WithSubjectDelimiter("")},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept subject with inconsistent spacing",
+			subject:         "OU=OrgUnit ,  CN = test-application , O=Organization,L=Waldorf, ST=Waldorf,C=DE",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept subject with a different attribute order and spacing after semicolons",
+			subject:         "C=DE; O=Organization; CN=test-application; OU=OrgUnit",
+			ops:             []Option{WithSubjectDelimiter(";")},
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject subject with malformed attributes",
+			subject:         "test-application,OU=OrgUnit",
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(`Hash=1;Subject="%s";URI=`, testCase.subject))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_EventValidator(t *testing.T) {
+	validEvent := `{"specversion":"1.0","id":"1","source":"/default/app","type":"order.created.v1"}`
+
+	testCases := []struct {
+		caseDescription string
+		path            string
+		body            string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "forward valid CloudEvent",
+			path:            fmt.Sprintf("/%s/events", applicationName),
+			body:            validEvent,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject CloudEvent missing required attributes with 400",
+			path:            fmt.Sprintf("/%s/events", applicationName),
+			body:            `{"specversion":"1.0","id":"1"}`,
+			expectedStatus:  http.StatusBadRequest,
+		},
+		{
+			caseDescription: "not validate legacy events",
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			body:            `{"specversion":"1.0","id":"1"}`,
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+				WithEventValidator(NewCloudEventValidator(0)))
+
+			// when
+			res := h.doWithBody(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), "application/cloudevents+json", testCase.body)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedStatus == http.StatusOK {
+				require.Len(t, h.receivedRequests(), 1)
+				assert.Equal(t, testCase.body, h.receivedRequests()[0].Body)
+			} else {
+				assert.Equal(t, ErrorCodeInvalidEvent, h.errorResponse(res).ErrorCode)
+				assert.Empty(t, h.receivedRequests())
+			}
+		})
+	}
+}
+
+func TestProxyHandler_EventTooLarge(t *testing.T) {
+	t.Run("should reject CloudEvent longer than the maximum body size with 413", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithEventValidator(NewCloudEventValidator(16)))
+
+		// when
+		res := h.doWithBody(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName),
+			"application/cloudevents+json", `{"specversion":"1.0","id":"1","source":"/default/app","type":"order.created.v1"}`)
+
+		// then
+		assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+		assert.Equal(t, ErrorCodeEventTooLarge, h.errorResponse(res).ErrorCode)
+		assert.Empty(t, h.receivedRequests())
+	})
+}
+
+func TestProxyHandler_LocationRewrite(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithLocationRewrite(DestinationCloudEvents, fmt.Sprintf("/%s/events/", applicationName)),
+		WithLocationRewrite(DestinationLegacyEvents, ""))
+	h.setUpstreamStatus(http.StatusFound)
+	eventPublisherProxyHost := strings.TrimPrefix(h.upstream.URL, "http://")
+
+	// the client must not follow the redirects, the test checks the Location returned by the proxy
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	testCases := []struct {
+		caseDescription  string
+		path             string
+		upstreamLocation string
+		expectedLocation string
+	}{
+		{
+			caseDescription:  "rewrite Location pointing at the destination host to public path",
+			path:             fmt.Sprintf("/%s/events", applicationName),
+			upstreamLocation: fmt.Sprintf("http://%s/publish/status?id=1", eventPublisherProxyHost),
+			expectedLocation: fmt.Sprintf("/%s/events/publish/status?id=1", applicationName),
+		},
+		{
+			caseDescription:  "rewrite Location pointing at a cluster-local service to public path",
+			path:             fmt.Sprintf("/%s/v2/events", applicationName),
+			upstreamLocation: "http://eventing-event-publisher-proxy.kyma-system.svc.cluster.local:8080/status",
+			expectedLocation: fmt.Sprintf("/%s/events/status", applicationName),
+		},
+		{
+			caseDescription:  "keep Location pointing at a public host",
+			path:             fmt.Sprintf("/%s/events", applicationName),
+			upstreamLocation: "https://example.com/status",
+			expectedLocation: "https://example.com/status",
+		},
+		{
+			caseDescription:  "keep relative Location",
+			path:             fmt.Sprintf("/%s/events", applicationName),
+			upstreamLocation: "/status",
+			expectedLocation: "/status",
+		},
+		{
+			caseDescription:  "strip internal Location without public path",
+			path:             fmt.Sprintf("/%s/v1/events", applicationName),
+			upstreamLocation: "http://eventing-event-publisher-proxy.kyma-system.svc/status",
+			expectedLocation: "",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h.setUpstreamHeader("Location", testCase.upstreamLocation)
+
+			// when
+			res, err := client.Do(h.newRequest(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), ""))
+			require.NoError(t, err)
+			defer res.Body.Close()
+
+			// then
+			assert.Equal(t, http.StatusFound, res.StatusCode)
+			assert.Equal(t, testCase.expectedLocation, res.Header.Get("Location"))
+		})
+	}
+}
+
+func TestProxyHandler_SubjectRejectionReason(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationWithClientIDs("secret-client-id")})
+
+	t.Run("should report the mismatching field without disclosing client IDs", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, "other-client"))
+
+		// then
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		var errorResponse httperrors.ErrorResponse
+		require.NoError(t, json.Unmarshal(body, &errorResponse))
+		assert.Equal(t, "no valid subject found: subject field CN does not match the application", errorResponse.Error)
+		assert.NotContains(t, string(body), "secret-client-id")
+
+		errorLogs := h.logs.FilterLevelExact(zap.ErrorLevel).All()
+		require.Len(t, errorLogs, 1)
+		reason := loggedContextField(errorLogs[0], "reason")
+		assert.Equal(t, "subject field CN does not match: expected one of 1 application client IDs, got 'other-client'", reason)
+	})
+}
+
+type blockingClientIDSource struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *blockingClientIDSource) GetClientIDs(_ context.Context, _ string) ([]string, error) {
+	s.calls.Add(1)
+	<-s.release
+	return []string{"configmap-client-id"}, nil
+}
+
+func TestProxyHandler_ConcurrentClientIDFetch(t *testing.T) {
+	t.Run("should fetch client IDs once for concurrent requests of the same application", func(t *testing.T) {
+		// given
+		source := &blockingClientIDSource{release: make(chan struct{})}
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithClientIDSource(source))
+
+		const requests = 20
+		statuses := make(chan int, requests)
+		var wg sync.WaitGroup
+
+		// when
+		for i := 0; i < requests; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				res := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, "configmap-client-id"))
+				statuses <- res.StatusCode
+			}()
+		}
+
+		require.Eventually(t, func() bool { return source.calls.Load() == 1 }, time.Second, time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		close(source.release)
+		wg.Wait()
+		close(statuses)
+
+		// then
+		assert.Equal(t, int32(1), source.calls.Load())
+		for status := range statuses {
+			assert.Equal(t, http.StatusOK, status)
+		}
+	})
+}
+
+// contextBlockingClientIDSource never answers, it returns only when the context of the fetch is done
+type contextBlockingClientIDSource struct {
+	calls atomic.Int32
+}
+
+func (s *contextBlockingClientIDSource) GetClientIDs(ctx context.Context, _ string) ([]string, error) {
+	s.calls.Add(1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestProxyHandler_ClientIDFetchTimeout(t *testing.T) {
+	t.Run("should fail requests and start new fetch after client ID source times out", func(t *testing.T) {
+		// given
+		source := &contextBlockingClientIDSource{}
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithClientIDSource(source), WithClientIDFetchTimeout(50*time.Millisecond))
+
+		// when
+		first := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+		second := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		for _, res := range []*http.Response{first, second} {
+			assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+			assert.Equal(t, ErrorCodeClientIDsUnavailable, h.errorResponse(res).ErrorCode)
+		}
+		assert.Equal(t, int32(2), source.calls.Load())
+		assert.Empty(t, h.receivedRequests())
+	})
+}
+
+// countingClientIDSource returns its client IDs and counts the fetches
+type countingClientIDSource struct {
+	calls     atomic.Int32
+	clientIDs []string
+}
+
+func (s *countingClientIDSource) GetClientIDs(_ context.Context, _ string) ([]string, error) {
+	s.calls.Add(1)
+	return s.clientIDs, nil
+}
+
+func TestProxyHandler_ClientIDCache(t *testing.T) {
+	t.Run("should reuse fetched client IDs until TTL expires", func(t *testing.T) {
+		// given
+		source := &countingClientIDSource{clientIDs: []string{"configmap-client-id"}}
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithClientIDSource(source), WithClientIDCacheTTL(100*time.Millisecond))
+		certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, "configmap-client-id")
+
+		// when
+		first := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+		second := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, first.StatusCode)
+		assert.Equal(t, http.StatusOK, second.StatusCode)
+		assert.Equal(t, int32(1), source.calls.Load())
+
+		// when
+		time.Sleep(150 * time.Millisecond)
+		third := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, third.StatusCode)
+		assert.Equal(t, int32(2), source.calls.Load())
+	})
+
+	t.Run("should reuse lack of client IDs", func(t *testing.T) {
+		// given
+		source := &countingClientIDSource{}
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithClientIDSource(source))
+		certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+		// when
+		first := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+		second := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, first.StatusCode)
+		assert.Equal(t, http.StatusOK, second.StatusCode)
+		assert.Equal(t, int32(1), source.calls.Load())
+	})
+
+	t.Run("should not cache failed fetches", func(t *testing.T) {
+		// given
+		source := &contextBlockingClientIDSource{}
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithClientIDSource(source), WithClientIDFetchTimeout(10*time.Millisecond))
+
+		// when
+		h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+		h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		assert.Equal(t, int32(2), source.calls.Load())
+	})
+}
+
+func TestProxyHandler_ConcurrencyLimit(t *testing.T) {
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	// newBlockingHarness creates the harness whose upstream blocks the requests with the X-Block header until release is closed
+	newBlockingHarness := func(t *testing.T, blocked *atomic.Int32, release chan struct{}, ops ...Option) *proxyTestHarness {
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, ops...)
+		h.setUpstreamHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Block") != "" {
+				blocked.Add(1)
+				<-release
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		return h
+	}
+
+	// startBlocked sends the blocking requests and waits until all of them reach the upstream
+	startBlocked := func(t *testing.T, h *proxyTestHarness, blocked *atomic.Int32, path string, count int) *sync.WaitGroup {
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			req := h.newRequest(http.MethodPost, path, certInfoHeader, "")
+			req.Header.Set("X-Block", "true")
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if res, err := http.DefaultClient.Do(req); err == nil {
+					res.Body.Close()
+				}
+			}()
+		}
+		require.Eventually(t, func() bool { return blocked.Load() == int32(count) }, time.Second, time.Millisecond)
+		return &wg
+	}
+
+	t.Run("should return 503 with Retry-After above the global limit", func(t *testing.T) {
+		// given
+		var blocked atomic.Int32
+		release := make(chan struct{})
+		h := newBlockingHarness(t, &blocked, release, WithMaxConcurrentRequests(2))
+		wg := startBlocked(t, h, &blocked, fmt.Sprintf("/%s/v1/events", applicationName), 2)
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+		close(release)
+		wg.Wait()
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, retryAfterSeconds, res.Header.Get("Retry-After"))
+		assert.Equal(t, http.StatusOK, h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader).StatusCode)
+	})
+
+	t.Run("should return 503 above the destination limit without limiting other destinations", func(t *testing.T) {
+		// given
+		var blocked atomic.Int32
+		release := make(chan struct{})
+		h := newBlockingHarness(t, &blocked, release,
+			WithMaxConcurrentRequests(10),
+			WithMaxConcurrentDestinationRequests(DestinationCloudEvents, 1))
+		wg := startBlocked(t, h, &blocked, fmt.Sprintf("/%s/events", applicationName), 1)
+
+		// when
+		cloudEvents := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+		legacyEvents := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), certInfoHeader)
+		close(release)
+		wg.Wait()
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, cloudEvents.StatusCode)
+		assert.Equal(t, retryAfterSeconds, cloudEvents.Header.Get("Retry-After"))
+		assert.Equal(t, http.StatusOK, legacyEvents.StatusCode)
+	})
+
+	for _, limit := range []int{0, -1} {
+		t.Run(fmt.Sprintf("should not limit the requests with the limit %d", limit), func(t *testing.T) {
+			// given
+			var blocked atomic.Int32
+			release := make(chan struct{})
+			h := newBlockingHarness(t, &blocked, release,
+				WithMaxConcurrentRequests(limit),
+				WithMaxConcurrentDestinationRequests(DestinationCloudEvents, limit))
+			wg := startBlocked(t, h, &blocked, fmt.Sprintf("/%s/events", applicationName), 2)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+			close(release)
+			wg.Wait()
+
+			// then
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+}
+
+func TestProxyHandler_StreamingResponse(t *testing.T) {
+	const firstChunk, secondChunk = "first-chunk\n", "second-chunk\n"
+
+	testCases := []struct {
+		caseDescription string
+		knownLength     bool
+		ops             []Option
+	}{
+		{
+			caseDescription: "deliver chunked response incrementally",
+		},
+		{
+			caseDescription: "deliver response with known length incrementally with flush interval",
+			knownLength:     true,
+			ops:             []Option{WithFlushInterval(DestinationCloudEvents, 10*time.Millisecond)},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			release := make(chan struct{})
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+			h.setUpstreamHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if testCase.knownLength {
+					w.Header().Set("Content-Length", strconv.Itoa(len(firstChunk)+len(secondChunk)))
+				}
+				_, _ = w.Write([]byte(firstChunk))
+				w.(http.Flusher).Flush()
+				<-release
+				_, _ = w.Write([]byte(secondChunk))
+			}))
+			defer close(release)
+
+			req := h.newRequest(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), "")
+
+			// when
+			firstLine := make(chan string, 1)
+			go func() {
+				res, err := http.DefaultClient.Do(req)
+				if err != nil {
+					firstLine <- err.Error()
+					return
+				}
+				defer res.Body.Close()
+				line, _ := bufio.NewReader(res.Body).ReadString('\n')
+				firstLine <- line
+			}()
+
+			// then
+			select {
+			case line := <-firstLine:
+				assert.Equal(t, firstChunk, line)
+			case <-time.After(2 * time.Second):
+				t.Fatal("first chunk not delivered before the upstream finished the response")
+			}
+		})
+	}
+}
+
+func TestProxyHandler_CircuitBreaker(t *testing.T) {
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	// newHarness creates the harness whose cloud events breaker reads the time from now
+	newHarness := func(t *testing.T, now *time.Time) *proxyTestHarness {
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithCircuitBreaker(DestinationCloudEvents, 3, 10*time.Second))
+		h.handler.(*proxyHandler).circuitBreakers[DestinationCloudEvents].now = func() time.Time { return *now }
+		return h
+	}
+
+	cloudEventsPath := fmt.Sprintf("/%s/events", applicationName)
+	legacyEventsPath := fmt.Sprintf("/%s/v1/events", applicationName)
+
+	t.Run("should open after the failure threshold and return 503 without calling the destination", func(t *testing.T) {
+		// given
+		now := time.Now()
+		h := newHarness(t, &now)
+		h.setUpstreamStatus(http.StatusInternalServerError)
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusBadGateway, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+		}
+
+		// when
+		res := h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, "10", res.Header.Get("Retry-After"))
+		assert.Len(t, h.receivedRequests(), 3)
+		assert.Equal(t, ErrorCodeCircuitOpen, h.errorResponse(res).ErrorCode)
+
+		h.setUpstreamStatus(http.StatusOK)
+		assert.Equal(t, http.StatusOK, h.do(http.MethodPost, legacyEventsPath, certInfoHeader).StatusCode)
+	})
+
+	t.Run("should not open when the failures are not consecutive", func(t *testing.T) {
+		// given
+		now := time.Now()
+		h := newHarness(t, &now)
+
+		// when
+		for _, status := range []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError, http.StatusInternalServerError} {
+			h.setUpstreamStatus(status)
+			h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+		}
+
+		// then
+		h.setUpstreamStatus(http.StatusOK)
+		assert.Equal(t, http.StatusOK, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+	})
+
+	t.Run("should close after a successful probe once the open timeout passed", func(t *testing.T) {
+		// given
+		now := time.Now()
+		h := newHarness(t, &now)
+		h.setUpstreamStatus(http.StatusInternalServerError)
+		for i := 0; i < 3; i++ {
+			h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+		}
+		h.setUpstreamStatus(http.StatusOK)
+		require.Equal(t, http.StatusServiceUnavailable, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+
+		// when
+		now = now.Add(10 * time.Second)
+		probe := h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, probe.StatusCode)
+		assert.Equal(t, http.StatusOK, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+	})
+
+	t.Run("should open again after a failed probe", func(t *testing.T) {
+		// given
+		now := time.Now()
+		h := newHarness(t, &now)
+		h.setUpstreamStatus(http.StatusInternalServerError)
+		for i := 0; i < 3; i++ {
+			h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+		}
+
+		// when
+		now = now.Add(10 * time.Second)
+		probe := h.do(http.MethodPost, cloudEventsPath, certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, probe.StatusCode)
+		h.setUpstreamStatus(http.StatusOK)
+		assert.Equal(t, http.StatusServiceUnavailable, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+	})
+
+	abortMidBody := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "1024")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("partial"))
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	})
+
+	doAborted := func(h *proxyTestHarness, certInfoHeader string) {
+		h.setUpstreamHandler(abortMidBody)
+		defer h.setUpstreamHandler(nil)
+
+		req, err := http.NewRequest(http.MethodPost, h.server.URL+cloudEventsPath, nil)
+		require.NoError(t, err)
+		req.Header.Set(CertificateInfoHeader, certInfoHeader)
+		if res, err := http.DefaultClient.Do(req); err == nil {
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+	}
+
+	t.Run("should count the response aborted mid-body as failure", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithCircuitBreaker(DestinationCloudEvents, 2, 10*time.Second))
+
+		// when
+		doAborted(h, certInfoHeader)
+		h.setUpstreamStatus(http.StatusInternalServerError)
+		require.Equal(t, http.StatusBadGateway, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+		h.setUpstreamStatus(http.StatusOK)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+	})
+
+	t.Run("should open again after the probe is aborted mid-body", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithCircuitBreaker(DestinationCloudEvents, 1, 100*time.Millisecond))
+		h.setUpstreamStatus(http.StatusInternalServerError)
+		require.Equal(t, http.StatusBadGateway, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+		h.setUpstreamStatus(http.StatusOK)
+		time.Sleep(100 * time.Millisecond)
+
+		// when
+		doAborted(h, certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, http.StatusOK, h.do(http.MethodPost, cloudEventsPath, certInfoHeader).StatusCode)
+	})
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	t.Run("should let the next request probe after the probe has not ended within the open timeout", func(t *testing.T) {
+		// given
+		now := time.Now()
+		breaker := newCircuitBreaker(1, 10*time.Second)
+		breaker.now = func() time.Time { return now }
+		breaker.record(true)
+		now = now.Add(10 * time.Second)
+		probeAllowed, _ := breaker.allow()
+		require.True(t, probeAllowed)
+
+		// when
+		now = now.Add(5 * time.Second)
+		allowedDuringProbe, retryAfter := breaker.allow()
+		now = now.Add(5 * time.Second)
+		allowedAfterProbeTimeout, _ := breaker.allow()
+
+		// then
+		assert.False(t, allowedDuringProbe)
+		assert.Equal(t, 5*time.Second, retryAfter)
+		assert.True(t, allowedAfterProbeTimeout)
+	})
+}
+
+func TestProxyHandler_SANURIValidation(t *testing.T) {
+	const uriPrefix = "spiffe://cluster.local/applications/"
+	const validSubject = `Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE"`
+	const invalidSubject = `Subject="CN=other-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE"`
+	const noOrganizationSubject = `Subject="CN=test-application,OU=OrgUnit,L=Waldorf,ST=Waldorf,C=DE"`
+	const validURI = "URI=" + uriPrefix + applicationName
+	const invalidURI = "URI=" + uriPrefix + "other-application"
+
+	const validCertificate = "Hash=1;" + validSubject + ";" + validURI
+	const forgedCertificate = `Hash=2;Subject="CN=forged";URI=spiffe://evil/x`
+
+	testCases := []struct {
+		caseDescription string
+		mode            SANValidationMode
+		ops             []Option
+		certInfoHeader  string
+		expectedStatus  int
+	}{
+		{
+			caseDescription: "ignore the URI when the validation is disabled",
+			mode:            SANValidationModeDisabled,
+			certInfoHeader:  "Hash=1;" + validSubject + ";" + invalidURI,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept valid subject and URI when the URI is required",
+			mode:            SANValidationModeRequired,
+			certInfoHeader:  "Hash=1;" + validSubject + ";" + validURI,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject invalid URI when the URI is required",
+			mode:            SANValidationModeRequired,
+			certInfoHeader:  "Hash=1;" + validSubject + ";" + invalidURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject missing URI when the URI is required",
+			mode:            SANValidationModeRequired,
+			certInfoHeader:  "Hash=1;" + validSubject + ";URI=",
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject invalid subject when the URI is required",
+			mode:            SANValidationModeRequired,
+			certInfoHeader:  "Hash=1;" + invalidSubject + ";" + validURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject valid subject and URI of different certificates when the URI is required",
+			mode:            SANValidationModeRequired,
+			certInfoHeader:  "Hash=1;" + validSubject + ";" + invalidURI + ",Hash=2;" + invalidSubject + ";" + validURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "accept valid URI with invalid subject when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			certInfoHeader:  "Hash=1;" + invalidSubject + ";" + validURI,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept valid URI among multiple URIs when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			certInfoHeader:  "Hash=1;" + invalidSubject + ";" + invalidURI + ";" + validURI,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept valid URI of certificate without subject when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			certInfoHeader:  "Hash=1;" + validURI,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "reject invalid subject and URI when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			certInfoHeader:  "Hash=1;" + invalidSubject + ";" + invalidURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject valid URI of subject missing required attribute when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			ops:             []Option{WithRequiredSubjectAttributes("O")},
+			certInfoHeader:  "Hash=1;" + noOrganizationSubject + ";" + validURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+		{
+			caseDescription: "reject valid URI of certificate without subject with required attributes when the URI is an alternative",
+			mode:            SANValidationModeAlternative,
+			ops:             []Option{WithRequiredSubjectAttributes("O")},
+			certInfoHeader:  "Hash=1;" + validURI,
+			expectedStatus:  http.StatusForbidden,
+		},
+	}
+
+	bundleModes := []struct {
+		subjectMode    SubjectValidationMode
+		expectedStatus int
+	}{
+		{subjectMode: SubjectValidationModeAny, expectedStatus: http.StatusOK},
+		{subjectMode: SubjectValidationModeAll, expectedStatus: http.StatusForbidden},
+	}
+	for _, sanMode := range []SANValidationMode{SANValidationModeDisabled, SANValidationModeRequired, SANValidationModeAlternative} {
+		for _, bundleMode := range bundleModes {
+			testCases = append(testCases, struct {
+				caseDescription string
+				mode            SANValidationMode
+				ops             []Option
+				certInfoHeader  string
+				expectedStatus  int
+			}{
+				caseDescription: fmt.Sprintf("validate valid and forged certificates in %s subject mode when the URI validation is %s", bundleMode.subjectMode, sanMode),
+				mode:            sanMode,
+				ops:             []Option{WithSubjectValidationMode(bundleMode.subjectMode)},
+				certInfoHeader:  validCertificate + "," + forgedCertificate,
+				expectedStatus:  bundleMode.expectedStatus,
+			})
+		}
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			ops := append([]Option{WithSANURIValidation(testCase.mode, uriPrefix)}, testCase.ops...)
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), testCase.certInfoHeader)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+		})
+	}
+}
+
+func TestSplitCertificateElements(t *testing.T) {
+	t.Run("should split elements of the certificate header outside of quoted values", func(t *testing.T) {
+		// when
+		elements := splitCertificateElements(`Hash=1;Subject="CN=app,O=Org";URI=spiffe://a,Hash=2;Subject="CN=\"x,y\"";URI=spiffe://b`)
+
+		// then
+		assert.Equal(t, []string{`Hash=1;Subject="CN=app,O=Org";URI=spiffe://a`, `Hash=2;Subject="CN=\"x,y\"";URI=spiffe://b`}, elements)
+	})
+}
+
+func TestProxyHandler_Backpressure(t *testing.T) {
+	testCases := []struct {
+		caseDescription    string
+		upstreamStatus     int
+		upstreamRetryAfter string
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{
+			caseDescription:    "keep the Retry-After of upstream 429",
+			upstreamStatus:     http.StatusTooManyRequests,
+			upstreamRetryAfter: "30",
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRetryAfter: "30",
+		},
+		{
+			caseDescription:    "set the default Retry-After on upstream 429 without it",
+			upstreamStatus:     http.StatusTooManyRequests,
+			expectedStatus:     http.StatusTooManyRequests,
+			expectedRetryAfter: retryAfterSeconds,
+		},
+		{
+			caseDescription:    "keep the status and the Retry-After of upstream 503",
+			upstreamStatus:     http.StatusServiceUnavailable,
+			upstreamRetryAfter: "30",
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "30",
+		},
+		{
+			caseDescription:    "keep the status and set the default Retry-After on upstream 503 without it",
+			upstreamStatus:     http.StatusServiceUnavailable,
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: retryAfterSeconds,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+			h.setUpstreamStatus(testCase.upstreamStatus)
+			if testCase.upstreamRetryAfter != "" {
+				h.setUpstreamHeader("Retry-After", testCase.upstreamRetryAfter)
+			}
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			assert.Equal(t, testCase.expectedRetryAfter, res.Header.Get("Retry-After"))
+			assert.Empty(t, res.Header.Get("Target-System-Status"))
+
+			warnLogs := h.logs.FilterLevelExact(zap.WarnLevel).All()
+			require.Len(t, warnLogs, 1)
+			assert.Equal(t, applicationName, loggedContextField(warnLogs[0], "applicationName"))
+			assert.Equal(t, testCase.expectedRetryAfter, loggedContextField(warnLogs[0], "retryAfter"))
+		})
+	}
+
+	t.Run("should rewrite upstream 500 to 502 without Retry-After", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+		h.setUpstreamStatus(http.StatusInternalServerError)
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+		assert.Equal(t, "500", res.Header.Get("Target-System-Status"))
+		assert.Empty(t, res.Header.Get("Retry-After"))
+	})
+}
+
+type inMemoryClientIDResolver map[string][]string
+
+func (r inMemoryClientIDResolver) ResolveClientIDs(_ context.Context, applicationName string) ([]string, error) {
+	clientIDs, found := r[applicationName]
+	if !found {
+		return nil, ErrApplicationNotFound
+	}
+	return clientIDs, nil
+}
+
+type failingClientIDResolver struct{}
+
+func (failingClientIDResolver) ResolveClientIDs(_ context.Context, _ string) ([]string, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func TestProxyHandler_ClientIDResolver(t *testing.T) {
+	testCases := []struct {
+		caseDescription   string
+		resolver          ClientIDResolver
+		commonName        string
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription: "accept the client ID of the resolver instead of the cached one",
+			resolver:        inMemoryClientIDResolver{applicationName: {"resolved-client-id"}},
+			commonName:      "resolved-client-id",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription:   "reject the cached client ID unknown to the resolver",
+			resolver:          inMemoryClientIDResolver{applicationName: {"resolved-client-id"}},
+			commonName:        "cached-client-id",
+			expectedStatus:    http.StatusForbidden,
+			expectedErrorCode: ErrorCodeForbiddenNoSubject,
+		},
+		{
+			caseDescription:   "return 404 for application unknown to the resolver",
+			resolver:          inMemoryClientIDResolver{},
+			commonName:        "resolved-client-id",
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeAppNotFound,
+		},
+		{
+			caseDescription:   "return 500 when the resolver fails",
+			resolver:          failingClientIDResolver{},
+			commonName:        "resolved-client-id",
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeClientIDsUnavailable,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationWithClientIDs("cached-client-id")}, WithClientIDResolver(testCase.resolver))
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, testCase.commonName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				assert.Equal(t, testCase.expectedErrorCode, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_ClientIDFetchFailurePolicy(t *testing.T) {
+	testCases := []struct {
+		caseDescription   string
+		ops               []Option
+		commonName        string
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription:   "return 500 when the resolver fails by default",
+			ops:               []Option{WithClientIDResolver(failingClientIDResolver{})},
+			commonName:        applicationName,
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeClientIDsUnavailable,
+		},
+		{
+			caseDescription:   "return 500 when the fallback source fails and the policy fails closed",
+			ops:               []Option{WithClientIDSource(failingClientIDSource{}), WithClientIDFetchFailurePolicy(ClientIDFetchFailurePolicyFailClosed)},
+			commonName:        applicationName,
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeClientIDsUnavailable,
+		},
+		{
+			caseDescription: "accept the application name when the resolver fails and the policy fails open",
+			ops:             []Option{WithClientIDResolver(failingClientIDResolver{}), WithClientIDFetchFailurePolicy(ClientIDFetchFailurePolicyFailOpenToCN)},
+			commonName:      applicationName,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept the application name when the fallback source fails and the policy fails open",
+			ops:             []Option{WithClientIDSource(failingClientIDSource{}), WithClientIDFetchFailurePolicy(ClientIDFetchFailurePolicyFailOpenToCN)},
+			commonName:      applicationName,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription:   "reject other common names when the policy fails open",
+			ops:               []Option{WithClientIDResolver(failingClientIDResolver{}), WithClientIDFetchFailurePolicy(ClientIDFetchFailurePolicyFailOpenToCN)},
+			commonName:        "client-id",
+			expectedStatus:    http.StatusForbidden,
+			expectedErrorCode: ErrorCodeForbiddenNoSubject,
+		},
+		{
+			caseDescription:   "return 404 for unknown application when the policy fails open",
+			ops:               []Option{WithClientIDResolver(inMemoryClientIDResolver{}), WithClientIDFetchFailurePolicy(ClientIDFetchFailurePolicyFailOpenToCN)},
+			commonName:        applicationName,
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeAppNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, testCase.commonName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				assert.Equal(t, testCase.expectedErrorCode, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_ValidationStrategyLog(t *testing.T) {
+	const uriPrefix = "spiffe://cluster.local/applications/"
+	const validURI = "URI=" + uriPrefix + applicationName
+
+	testCases := []struct {
+		caseDescription  string
+		clientIDs        []string
+		ops              []Option
+		commonName       string
+		uri              string
+		expectedStrategy string
+	}{
+		{
+			caseDescription:  "application name for application without client IDs",
+			commonName:       applicationName,
+			uri:              "URI=",
+			expectedStrategy: ValidationStrategyApplicationName,
+		},
+		{
+			caseDescription:  "client ID for application with client IDs",
+			clientIDs:        []string{"client-id"},
+			commonName:       "client-id",
+			uri:              "URI=",
+			expectedStrategy: ValidationStrategyClientID,
+		},
+		{
+			caseDescription:  "custom for replaced subject validator",
+			ops:              []Option{WithSubjectValidator(commonNameRegexValidator{regex: regexp.MustCompile(`^tenant-[a-z]+$`)})},
+			commonName:       "tenant-abc",
+			uri:              "URI=",
+			expectedStrategy: ValidationStrategyCustom,
+		},
+		{
+			caseDescription:  "both strategies when the URI is required",
+			clientIDs:        []string{"client-id"},
+			ops:              []Option{WithSANURIValidation(SANValidationModeRequired, uriPrefix)},
+			commonName:       "client-id",
+			uri:              validURI,
+			expectedStrategy: ValidationStrategyClientID + "+" + ValidationStrategySANURI,
+		},
+		{
+			caseDescription:  "URI when only the URI is valid in the alternative mode",
+			ops:              []Option{WithSANURIValidation(SANValidationModeAlternative, uriPrefix)},
+			commonName:       "other-application",
+			uri:              validURI,
+			expectedStrategy: ValidationStrategySANURI,
+		},
+		{
+			caseDescription:  "subject strategy when the subject is valid in the alternative mode",
+			ops:              []Option{WithSANURIValidation(SANValidationModeAlternative, uriPrefix)},
+			commonName:       applicationName,
+			uri:              validURI,
+			expectedStrategy: ValidationStrategyApplicationName,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should report "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			application := applicationNotManagedByCompass
+			if testCase.clientIDs != nil {
+				application = applicationWithClientIDs(testCase.clientIDs...)
+			}
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{application},
+				append(testCase.ops, WithValidationStrategyLog(), WithLogSampling(100))...)
+			certInfoHeader := fmt.Sprintf(`Hash=1;Subject="CN=%s,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";%s`, testCase.commonName, testCase.uri)
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+			h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+
+			// then
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			entries := h.logs.FilterMessage("Identity of the request approved").All()
+			require.Len(t, entries, 2)
+			assert.Equal(t, testCase.expectedStrategy, loggedContextField(entries[0], "validationStrategy"))
+		})
+	}
+
+	t.Run("should not report the strategy by default", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+
+		// when
+		h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		assert.Zero(t, h.logs.FilterMessage("Identity of the request approved").Len())
+	})
+}
+
+func TestProxyHandler_LogSampling(t *testing.T) {
+	eventsPath := fmt.Sprintf("/%s/events", applicationName)
+	validCertInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+	invalidCertInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, "other-application")
+
+	t.Run("should log 1 in rate successful requests", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithLogSampling(5))
+
+		// when
+		for i := 0; i < 10; i++ {
+			h.do(http.MethodPost, eventsPath, validCertInfoHeader)
+		}
+
+		// then
+		assert.Len(t, h.logs.FilterMessage("Proxying request for application...").All(), 2)
+		assert.Len(t, h.logs.FilterMessage("Proxying request to target URL...").All(), 2)
+		assert.Len(t, h.logs.FilterMessage("Host responded with status 200 OK").All(), 2)
+	})
+
+	t.Run("should always log errors", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithLogSampling(5))
+		h.setUpstreamStatus(http.StatusInternalServerError)
+
+		// when
+		for i := 0; i < 10; i++ {
+			h.do(http.MethodPost, eventsPath, invalidCertInfoHeader)
+			h.do(http.MethodPost, eventsPath, validCertInfoHeader)
+		}
+
+		// then
+		assert.Len(t, h.logs.FilterLevelExact(zap.ErrorLevel).All(), 10)
+		assert.Len(t, h.logs.FilterMessage("Host responded with status 500 Internal Server Error").All(), 10)
+	})
+
+	t.Run("should log all requests without sampling", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+
+		// when
+		for i := 0; i < 10; i++ {
+			h.do(http.MethodPost, eventsPath, validCertInfoHeader)
+		}
+
+		// then
+		assert.Len(t, h.logs.FilterMessage("Proxying request for application...").All(), 10)
+	})
+}
+
+func TestNewProxyHandlerFromConfig(t *testing.T) {
+	log, err := logger.New(logger.TEXT, logger.ERROR)
+	require.NoError(t, err)
+
+	eventPublisherProxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Upstream-Path", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer eventPublisherProxyServer.Close()
+	eventPublisherProxyHost := strings.TrimPrefix(eventPublisherProxyServer.URL, "http://")
+
+	idCache := cache.New(time.Minute, time.Minute)
+	idCache.Set(applicationName, controller.CachedAppData{
+		AppPathPrefixV1:     fmt.Sprintf("/%s/v1/events", applicationName),
+		AppPathPrefixV2:     fmt.Sprintf("/%s/v2/events", applicationName),
+		AppPathPrefixEvents: fmt.Sprintf("/%s/events", applicationName),
+	}, cache.NoExpiration)
+
+	serve := func(proxyHandler ProxyHandler, path string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(http.MethodPost, path, nil)
+		require.NoError(t, err)
+		req.Header.Set(CertificateInfoHeader, fmt.Sprintf(`Hash=1;Subject="CN=%s,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`, applicationName))
+		req = mux.SetURLVars(req, map[string]string{"application": applicationName})
+
+		recorder := httptest.NewRecorder()
+		proxyHandler.ProxyAppConnectorRequests(recorder, req)
+		return recorder
+	}
+
+	testCases := []struct {
+		caseDescription         string
+		eventingPublisherHost   string
+		eventingDestinationPath string
+		valid                   bool
+	}{
+		{
+			caseDescription:         "host with port",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system:8080",
+			eventingDestinationPath: "/publish",
+			valid:                   true,
+		},
+		{
+			caseDescription:         "IPv6 host with port",
+			eventingPublisherHost:   "[::1]:8080",
+			eventingDestinationPath: "/publish",
+			valid:                   true,
+		},
+		{
+			caseDescription:         "empty host",
+			eventingPublisherHost:   "",
+			eventingDestinationPath: "/publish",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "host with scheme",
+			eventingPublisherHost:   "http://eventing-event-publisher-proxy.kyma-system",
+			eventingDestinationPath: "/publish",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "host with path",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system/publish",
+			eventingDestinationPath: "/publish",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "host with invalid port",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system:80800",
+			eventingDestinationPath: "/publish",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "empty destination path",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system",
+			eventingDestinationPath: "",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "relative destination path",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system",
+			eventingDestinationPath: "publish",
+			valid:                   false,
+		},
+		{
+			caseDescription:         "destination path with query",
+			eventingPublisherHost:   "eventing-event-publisher-proxy.kyma-system",
+			eventingDestinationPath: "/publish?async=true",
+			valid:                   false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should validate "+testCase.caseDescription, func(t *testing.T) {
+			// when
+			proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+				EventingPublisherHost:   testCase.eventingPublisherHost,
+				EventingDestinationPath: testCase.eventingDestinationPath,
+			}, cache.New(time.Minute, time.Minute), log)
+
+			// then
+			assert.Equal(t, testCase.valid, err == nil, "error: %v", err)
+			assert.Equal(t, testCase.valid, proxyHandler != nil)
+		})
+	}
+
+	t.Run("should behave like the positional constructor", func(t *testing.T) {
+		// given
+		fromConfig, err := NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   eventPublisherProxyHost,
+			EventingDestinationPath: eventingDestinationPathPublish,
+		}, idCache, log, WithAllowedMethods(DestinationCloudEvents, http.MethodPost))
+		require.NoError(t, err)
+		positional := NewProxyHandler(eventPublisherProxyHost, eventingDestinationPathPublish, idCache, log, WithAllowedMethods(DestinationCloudEvents, http.MethodPost))
+
+		for _, path := range []string{
+			fmt.Sprintf("/%s/events", applicationName),
+			fmt.Sprintf("/%s/v1/events", applicationName),
+			fmt.Sprintf("/%s/unknown", applicationName),
+		} {
+			// when
+			expected := serve(positional, path)
+			actual := serve(fromConfig, path)
+
+			// then
+			assert.Equal(t, expected.Code, actual.Code, path)
+			assert.Equal(t, expected.Header().Get("X-Upstream-Path"), actual.Header().Get("X-Upstream-Path"), path)
+		}
+	})
+
+	t.Run("should reject swapped host and path", func(t *testing.T) {
+		// when
+		proxyHandler, err := NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   eventingDestinationPathPublish,
+			EventingDestinationPath: eventPublisherProxyHost,
+		}, idCache, log)
+
+		// then
+		require.Error(t, err)
+		assert.Nil(t, proxyHandler)
+	})
+
+	t.Run("should not validate host and path in the positional constructor", func(t *testing.T) {
+		// given
+		proxyHandler := NewProxyHandler(eventingDestinationPathPublish, eventPublisherProxyHost, idCache, log)
+
+		// when
+		recorder := serve(proxyHandler, fmt.Sprintf("/%s/events", applicationName))
+
+		// then
+		assert.Equal(t, http.StatusBadGateway, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("X-Upstream-Path"))
+
+		var errorResponse httperrors.ErrorResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &errorResponse))
+		assert.Equal(t, ErrorCodeUpstreamUnreachable, errorResponse.ErrorCode)
+	})
+}
+
+func TestProxyHandler_RequestHooks(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithRequestHooks(DestinationCloudEvents, func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer token")
+		}, func(r *http.Request) {
+			r.Host = "hooked-host"
+		}),
+		WithRequestHooks("unknown", func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer unknown")
+		}))
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	t.Run("should run the hooks after the built-in rewrites of the destination", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		received := h.receivedRequests()
+		require.NotEmpty(t, received)
+		assert.Equal(t, "Bearer token", received[len(received)-1].Header.Get("Authorization"))
+		assert.Equal(t, "hooked-host", received[len(received)-1].Host)
+	})
+
+	t.Run("should not run the hooks for other destinations", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		received := h.receivedRequests()
+		require.NotEmpty(t, received)
+		assert.Empty(t, received[len(received)-1].Header.Get("Authorization"))
+		assert.Equal(t, strings.TrimPrefix(h.upstream.URL, "http://"), received[len(received)-1].Host)
+	})
+}
+
+func TestProxyHandler_ErrorContentNegotiation(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+
+	testCases := []struct {
+		caseDescription     string
+		accept              string
+		expectedContentType string
+	}{
+		{
+			caseDescription:     "JSON without the Accept header",
+			expectedContentType: httpconsts.ContentTypeApplicationJson,
+		},
+		{
+			caseDescription:     "JSON for Accept: application/json",
+			accept:              "application/json",
+			expectedContentType: httpconsts.ContentTypeApplicationJson,
+		},
+		{
+			caseDescription:     "plain text for Accept: text/plain",
+			accept:              "text/plain",
+			expectedContentType: httpconsts.ContentTypeTextPlain,
+		},
+		{
+			caseDescription:     "JSON for any media type",
+			accept:              "*/*",
+			expectedContentType: httpconsts.ContentTypeApplicationJson,
+		},
+		{
+			caseDescription:     "plain text preferred by quality",
+			accept:              "application/json;q=0.5, text/plain",
+			expectedContentType: httpconsts.ContentTypeTextPlain,
+		},
+		{
+			caseDescription:     "JSON preferred by quality",
+			accept:              "text/plain;q=0.2, application/json;q=0.8",
+			expectedContentType: httpconsts.ContentTypeApplicationJson,
+		},
+		{
+			caseDescription:     "JSON for unsupported media type",
+			accept:              "application/xml",
+			expectedContentType: httpconsts.ContentTypeApplicationJson,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should respond with "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			req := h.newRequest(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), "", "")
+			if testCase.accept != "" {
+				req.Header.Set("Accept", testCase.accept)
+			}
+
+			// when
+			res := h.send(req)
+
+			// then
+			assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+			assert.Equal(t, testCase.expectedContentType, res.Header.Get(httpconsts.HeaderContentType))
+			if testCase.expectedContentType == httpconsts.ContentTypeTextPlain {
+				body, err := io.ReadAll(res.Body)
+				require.NoError(t, err)
+				assert.Equal(t, fmt.Sprintf("%s: %s header not found\n", ErrorCodeCertificateHeaderNotFound, CertificateInfoHeader), string(body))
+			} else {
+				assert.Equal(t, ErrorCodeCertificateHeaderNotFound, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_FallbackDestination(t *testing.T) {
+	namedUpstream := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Upstream", name)
+			w.Header().Set("X-Upstream-Path", r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	catchAllServer := httptest.NewServer(namedUpstream("catch-all"))
+	t.Cleanup(catchAllServer.Close)
+
+	catchAllRoute := EventsAPIRoute{
+		Destination:        "catch-all",
+		PathPrefix:         "/%%APP_NAME%%/catch-all",
+		AppNamePlaceholder: "%%APP_NAME%%",
+		DestinationHost:    strings.TrimPrefix(catchAllServer.URL, "http://"),
+	}
+
+	testCases := []struct {
+		caseDescription  string
+		ops              []Option
+		path             string
+		expectedStatus   int
+		expectedUpstream string
+		expectedPath     string
+	}{
+		{
+			caseDescription:  "proxy matched path to its destination with fallback",
+			ops:              []Option{WithEventsAPIRoutes(catchAllRoute), WithFallbackDestination("catch-all")},
+			path:             fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:   http.StatusOK,
+			expectedUpstream: "default",
+			expectedPath:     fmt.Sprintf("/%s/v1/events", applicationName),
+		},
+		{
+			caseDescription:  "proxy unmatched path to the fallback destination",
+			ops:              []Option{WithEventsAPIRoutes(catchAllRoute), WithFallbackDestination("catch-all")},
+			path:             fmt.Sprintf("/%s/metadata/v1/services", applicationName),
+			expectedStatus:   http.StatusOK,
+			expectedUpstream: "catch-all",
+			expectedPath:     fmt.Sprintf("/%s/metadata/v1/services", applicationName),
+		},
+		{
+			caseDescription: "return 404 for unmatched path without fallback",
+			ops:             []Option{WithEventsAPIRoutes(catchAllRoute)},
+			path:            fmt.Sprintf("/%s/metadata/v1/services", applicationName),
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			caseDescription: "return 404 for unmatched path with unknown fallback",
+			ops:             []Option{WithFallbackDestination("catch-all")},
+			path:            fmt.Sprintf("/%s/metadata/v1/services", applicationName),
+			expectedStatus:  http.StatusNotFound,
+		},
+		{
+			caseDescription: "return 404 for unmatched path with disabled fallback",
+			ops:             []Option{WithEventsAPIRoutes(catchAllRoute), WithFallbackDestination("catch-all"), WithDisabledDestinations("catch-all")},
+			path:            fmt.Sprintf("/%s/metadata/v1/services", applicationName),
+			expectedStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, testCase.ops...)
+			h.setUpstreamHandler(namedUpstream("default"))
+
+			// when
+			res := h.do(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			assert.Equal(t, testCase.expectedUpstream, res.Header.Get("X-Upstream"))
+			assert.Equal(t, testCase.expectedPath, res.Header.Get("X-Upstream-Path"))
+		})
+	}
+}
+
+func TestProxyHandler_AllowedDestinations(t *testing.T) {
+	testCases := []struct {
+		caseDescription     string
+		allowedDestinations []string
+		path                string
+		expectedStatus      int
+		expectedErrorCode   string
+	}{
+		{
+			caseDescription:     "proxy request to allowed destination",
+			allowedDestinations: []string{string(DestinationCloudEvents)},
+			path:                fmt.Sprintf("/%s/events", applicationName),
+			expectedStatus:      http.StatusOK,
+		},
+		{
+			caseDescription:     "return 403 for destination not allowed for application",
+			allowedDestinations: []string{string(DestinationCloudEvents)},
+			path:                fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:      http.StatusForbidden,
+			expectedErrorCode:   ErrorCodeDestinationNotAllowed,
+		},
+		{
+			caseDescription:     "return 403 for application without allowed destinations",
+			allowedDestinations: []string{},
+			path:                fmt.Sprintf("/%s/events", applicationName),
+			expectedStatus:      http.StatusForbidden,
+			expectedErrorCode:   ErrorCodeDestinationNotAllowed,
+		},
+		{
+			caseDescription: "proxy request to any destination without allowlist",
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			application := applicationNotManagedByCompass.DeepCopy()
+			if testCase.allowedDestinations != nil {
+				application.Annotations = map[string]string{controller.AllowedDestinationsAnnotation: strings.Join(testCase.allowedDestinations, ",")}
+			}
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{application})
+
+			// when
+			res := h.do(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				assert.Equal(t, testCase.expectedErrorCode, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
+
+func TestProxyHandler_OriginalHost(t *testing.T) {
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithOriginalHost(DestinationCloudEvents))
+
+	testCases := []struct {
+		caseDescription string
+		path            string
+		expectedHost    string
+	}{
+		{
+			caseDescription: "forward the original host to the destination preserving it",
+			path:            fmt.Sprintf("/%s/events", applicationName),
+			expectedHost:    "gateway.example.com",
+		},
+		{
+			caseDescription: "replace the original host for other destinations",
+			path:            fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedHost:    strings.TrimPrefix(h.upstream.URL, "http://"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			req := h.newRequest(http.MethodPost, testCase.path, fmt.Sprintf(harnessCertInfoHeaderValue, applicationName), "")
+			req.Host = "gateway.example.com"
+
+			// when
+			res := h.send(req)
+
+			// then
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			received := h.receivedRequests()
+			require.NotEmpty(t, received)
+			assert.Equal(t, testCase.expectedHost, received[len(received)-1].Host)
+		})
+	}
+}
+
+func TestProxyHandler_EventsAPIRoutes(t *testing.T) {
+	namedUpstream := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Upstream", name)
+			w.Header().Set("X-Upstream-Path", r.URL.Path)
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+	v3PublisherServer := httptest.NewServer(namedUpstream("v3"))
+	t.Cleanup(v3PublisherServer.Close)
+
+	const v3Destination Destination = "v3-events"
+	v3Route := EventsAPIRoute{
+		Destination:        v3Destination,
+		PathPrefix:         "/%%APP_NAME%%/v3/events",
+		AppNamePlaceholder: "%%APP_NAME%%",
+		DestinationHost:    strings.TrimPrefix(v3PublisherServer.URL, "http://"),
+		DestinationPath:    "/v3/publish",
+	}
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+		WithEventsAPIRoutes(v3Route),
+		WithAllowedMethods(v3Destination, http.MethodPost))
+	h.setUpstreamHandler(namedUpstream("default"))
+
+	t.Run("should route the additional version prefix to the configured host and path", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v3/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "v3", res.Header.Get("X-Upstream"))
+		assert.Equal(t, "/v3/publish", res.Header.Get("X-Upstream-Path"))
+	})
+
+	t.Run("should keep the default routes", func(t *testing.T) {
+		// when
+		legacyEventsRes := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), certInfoHeader)
+		cloudEventsRes := h.do(http.MethodPost, fmt.Sprintf("/%s/v2/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, "default", legacyEventsRes.Header.Get("X-Upstream"))
+		assert.Equal(t, fmt.Sprintf("/%s/v1/events", applicationName), legacyEventsRes.Header.Get("X-Upstream-Path"))
+		assert.Equal(t, "default", cloudEventsRes.Header.Get("X-Upstream"))
+		assert.Equal(t, eventingDestinationPathPublish, cloudEventsRes.Header.Get("X-Upstream-Path"))
+	})
+
+	t.Run("should apply the destination options to the additional destination", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPut, fmt.Sprintf("/%s/v3/events", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	})
+
+	t.Run("should not route the prefix of another application", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, "/other-application/v3/events", certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should apply the destination options given before the routes", func(t *testing.T) {
+		// given
+		optionsFirst := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass},
+			WithFallbackDestination(v3Destination),
+			WithRequestHooks(v3Destination, func(r *http.Request) { r.URL.Path = "/v3/hooked" }),
+			WithEventsAPIRoutes(v3Route))
+
+		// when
+		res := optionsFirst.do(http.MethodPost, fmt.Sprintf("/%s/unknown", applicationName), certInfoHeader)
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "v3", res.Header.Get("X-Upstream"))
+		assert.Equal(t, "/v3/hooked", res.Header.Get("X-Upstream-Path"))
+	})
+}
+
+func TestProxyHandler_ConfigLog(t *testing.T) {
+	t.Run("should log the effective configuration once with masked credentials", func(t *testing.T) {
+		// given
+		core, observedLogs := observer.New(zap.InfoLevel)
+		log, err := logger.New(logger.TEXT, logger.ERROR, core)
+		require.NoError(t, err)
+
+		// when
+		_, err = NewProxyHandlerFromConfig(ProxyConfig{
+			EventingPublisherHost:   "eventing-publisher:8080",
+			EventingDestinationPath: eventingDestinationPathPublish,
+		}, cache.New(time.Minute, time.Minute), log,
+			WithEventsAPIRoutes(EventsAPIRoute{
+				Destination:        "v3-events",
+				PathPrefix:         "/%%APP_NAME%%/v3/events",
+				AppNamePlaceholder: "%%APP_NAME%%",
+				DestinationHost:    "user:secret-password@eventing-v3:8080",
+			}),
+			WithResponseHeaderTimeout(DestinationCloudEvents, 5*time.Second),
+			WithMaxConcurrentDestinationRequests(DestinationLegacyEvents, 10),
+			WithCircuitBreaker(DestinationCloudEvents, 3, time.Minute),
+			WithSubjectValidationMode(SubjectValidationModeAll))
+
+		// then
+		require.NoError(t, err)
+		configLogs := observedLogs.FilterMessage("Proxy handler configured").All()
+		require.Len(t, configLogs, 1)
+
+		loggedConfig, err := json.Marshal(loggedContextField(configLogs[0], "config"))
+		require.NoError(t, err)
+
+		var config handlerConfig
+		require.NoError(t, json.Unmarshal(loggedConfig, &config))
+		assert.Equal(t, SubjectValidationModeAll, config.SubjectValidationMode)
+		require.Len(t, config.Destinations, 3)
+
+		assert.Equal(t, destinationConfig{
+			Name:                  DestinationLegacyEvents,
+			Host:                  "eventing-publisher:8080",
+			ResponseHeaderTimeout: "0s",
+			FlushInterval:         "0s",
+			MaxConcurrent:         10,
+		}, config.Destinations[0])
+		assert.Equal(t, destinationConfig{
+			Name:                    DestinationCloudEvents,
+			Host:                    "eventing-publisher:8080",
+			Path:                    eventingDestinationPathPublish,
+			ResponseHeaderTimeout:   "5s",
+			FlushInterval:           "0s",
+			CircuitBreakerThreshold: 3,
+			CircuitBreakerTimeout:   "1m0s",
+		}, config.Destinations[1])
+		assert.Equal(t, "/%%APP_NAME%%/v3/events", config.Destinations[2].PathPrefix)
+		assert.Equal(t, "***@eventing-v3:8080", config.Destinations[2].Host)
+		assert.NotContains(t, string(loggedConfig), "secret-password")
+	})
+}
+
+func TestProxyHandler_MaxCertificateHeaderLength(t *testing.T) {
+	certInfoHeader := fmt.Sprintf(harnessCertInfoHeaderValue, applicationName)
+
+	testCases := []struct {
+		caseDescription   string
+		maxLength         int
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription: "accept the header under the limit",
+			maxLength:       len(certInfoHeader) + 1,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription: "accept the header at the limit",
+			maxLength:       len(certInfoHeader),
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription:   "reject the header over the limit with 431",
+			maxLength:         len(certInfoHeader) - 1,
+			expectedStatus:    http.StatusRequestHeaderFieldsTooLarge,
+			expectedErrorCode: ErrorCodeCertificateHeaderTooLarge,
+		},
+		{
+			caseDescription: "accept the header without the limit",
+			maxLength:       0,
+			expectedStatus:  http.StatusOK,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass}, WithMaxCertificateHeaderLength(testCase.maxLength))
+
+			// when
+			res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), certInfoHeader)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				assert.Equal(t, testCase.expectedErrorCode, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package validationproxy
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// handlerConfig is the effective configuration of the proxy handler, safe to log
+type handlerConfig struct {
+	SubjectValidationMode SubjectValidationMode      `json:"subjectValidationMode"`
+	SubjectDelimiter      string                     `json:"subjectDelimiter"`
+	SubjectAttributes     SubjectAttributes          `json:"subjectAttributes"`
+	RequiredAttributes    []string                   `json:"requiredSubjectAttributes,omitempty"`
+	MaxCertHeaderLength   int                        `json:"maxCertHeaderLength"`
+	SANValidationMode     SANValidationMode          `json:"sanValidationMode"`
+	SANURIPrefix          string                     `json:"sanURIPrefix,omitempty"`
+	DefaultOrganization   string                     `json:"defaultOrganization,omitempty"`
+	DefaultOrgUnit        string                     `json:"defaultOrganizationalUnit,omitempty"`
+	ClientIDSource        bool                       `json:"clientIDSource"`
+	ClientIDFailure       ClientIDFetchFailurePolicy `json:"clientIDFetchFailurePolicy"`
+	ClientIDTimeout       string                     `json:"clientIDFetchTimeout"`
+	ClientIDCacheTTL      string                     `json:"clientIDCacheTTL"`
+	EventValidation       bool                       `json:"eventValidation"`
+	LogSamplingRate       uint64                     `json:"logSamplingRate"`
+	PathRedactionPatterns []string                   `json:"pathRedactionPatterns,omitempty"`
+	MaxConcurrent         int                        `json:"maxConcurrent"`
+	DecisionLogSize       int                        `json:"decisionLogSize"`
+	AppCountersIdle       string                     `json:"applicationCountersIdleTimeout,omitempty"`
+	AppCountersMax        int                        `json:"applicationCountersMaxApplications,omitempty"`
+	FallbackDestination   Destination                `json:"fallbackDestination,omitempty"`
+	Destinations          []destinationConfig        `json:"destinations"`
+}
+
+type destinationConfig struct {
+	Name                    Destination `json:"name"`
+	PathPrefix              string      `json:"pathPrefix,omitempty"`
+	Host                    string      `json:"host"`
+	Path                    string      `json:"path,omitempty"`
+	Disabled                bool        `json:"disabled"`
+	OriginalHost            bool        `json:"originalHost"`
+	AllowedMethods          []string    `json:"allowedMethods,omitempty"`
+	ResponseHeaderTimeout   string      `json:"responseHeaderTimeout"`
+	FlushInterval           string      `json:"flushInterval"`
+	MaxConcurrent           int         `json:"maxConcurrent"`
+	CircuitBreakerThreshold int         `json:"circuitBreakerThreshold"`
+	CircuitBreakerTimeout   string      `json:"circuitBreakerOpenTimeout,omitempty"`
+}
+
+func (ph *proxyHandler) config() handlerConfig {
+	config := handlerConfig{
+		SubjectValidationMode: ph.subjectValidationMode,
+		SubjectDelimiter:      ph.subjectDelimiter,
+		SubjectAttributes:     ph.subjectAttributes,
+		RequiredAttributes:    ph.requiredAttributes,
+		MaxCertHeaderLength:   ph.maxCertHeaderLength,
+		SANValidationMode:     ph.sanValidationMode,
+		SANURIPrefix:          ph.sanURIPrefix,
+		DefaultOrganization:   ph.defaultOrganization,
+		DefaultOrgUnit:        ph.defaultOrganizationalUnit,
+		ClientIDSource:        ph.clientIDSource != nil,
+		ClientIDFailure:       ph.clientIDFailure,
+		ClientIDTimeout:       ph.clientIDTimeout.String(),
+		ClientIDCacheTTL:      ph.clientIDCacheTTL.String(),
+		EventValidation:       ph.eventValidator != nil,
+		LogSamplingRate:       1,
+		MaxConcurrent:         cap(ph.concurrencyLimit),
+		FallbackDestination:   ph.fallbackDestination,
+	}
+	if ph.decisionLog != nil {
+		config.DecisionLogSize = len(ph.decisionLog.decisions)
+	}
+	if ph.appCounters != nil {
+		config.AppCountersIdle = ph.appCounters.idleTimeout.String()
+		config.AppCountersMax = ph.appCounters.maxApplications
+	}
+	if ph.logSampler != nil {
+		config.LogSamplingRate = ph.logSampler.rate
+	}
+	for _, pattern := range ph.pathRedactor.patterns {
+		config.PathRedactionPatterns = append(config.PathRedactionPatterns, pattern.String())
+	}
+
+	config.Destinations = append(config.Destinations,
+		ph.destinationConfig(DestinationLegacyEvents, "", ph.eventingPublisherHost, ""),
+		ph.destinationConfig(DestinationCloudEvents, "", ph.eventingPublisherHost, ph.eventingDestinationPath))
+	for _, route := range ph.eventsAPIRoutes {
+		config.Destinations = append(config.Destinations, ph.destinationConfig(route.Destination, route.PathPrefix, route.DestinationHost, route.DestinationPath))
+	}
+
+	return config
+}
+
+func (ph *proxyHandler) destinationConfig(destination Destination, pathPrefix, host, path string) destinationConfig {
+	config := destinationConfig{
+		Name:           destination,
+		PathPrefix:     pathPrefix,
+		Host:           maskHostCredentials(host),
+		Path:           path,
+		Disabled:       ph.disabledDestinations[destination],
+		OriginalHost:   ph.preservedHosts[destination],
+		AllowedMethods: ph.allowedMethods[destination],
+		MaxConcurrent:  cap(ph.destinationConcurrencyLimit[destination]),
+	}
+	if breaker := ph.circuitBreakers[destination]; breaker != nil {
+		config.CircuitBreakerThreshold = breaker.failureThreshold
+		config.CircuitBreakerTimeout = breaker.openTimeout.String()
+	}
+	if proxy := ph.destinationProxy(destination); proxy != nil {
+		config.ResponseHeaderTimeout = responseHeaderTimeout(proxy)
+		config.FlushInterval = proxy.FlushInterval.String()
+	}
+	return config
+}
+
+func responseHeaderTimeout(proxy *httputil.ReverseProxy) string {
+	if transport, ok := proxy.Transport.(*http.Transport); ok {
+		return transport.ResponseHeaderTimeout.String()
+	}
+	return "custom transport"
+}
+
+// maskHostCredentials replaces the user info of the host, which must not be logged
+func maskHostCredentials(host string) string {
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		return redactedValue + host[i:]
+	}
+	return host
+}
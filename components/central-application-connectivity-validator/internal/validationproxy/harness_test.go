@@ -0,0 +1,201 @@
+package validationproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kyma-project/kyma/common/logging/logger"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/httperrors"
+	appconnv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const (
+	harnessHealthPath          = "/healthz"
+	harnessAppNamePlaceholder  = "%%APP_NAME%%"
+	harnessPathPrefixV1        = "/%%APP_NAME%%/v1/events"
+	harnessPathPrefixV2        = "/%%APP_NAME%%/v2/events"
+	harnessPathPrefixEvents    = "/%%APP_NAME%%/events"
+	harnessCertInfoHeaderValue = `Hash=1;Subject="CN=%s,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`
+)
+
+// upstreamRequest is a request received by the upstream of the proxy test harness
+type upstreamRequest struct {
+	Method string
+	Host   string
+	Path   string
+	Header http.Header
+	Body   string
+}
+
+// proxyTestHarness runs the proxy end to end: the router, the handler, and the cache filled by the cache sync
+// from the Applications served by a fake client. Requests are sent to a real HTTP server and proxied to an upstream
+// recording them.
+type proxyTestHarness struct {
+	t        *testing.T
+	server   *httptest.Server
+	upstream *httptest.Server
+	appCache *cache.Cache
+	handler  ProxyHandler
+	logs     *observer.ObservedLogs
+
+	mu               sync.Mutex
+	upstreamStatus   int
+	upstreamHeader   http.Header
+	upstreamHandler  http.Handler
+	upstreamRequests []upstreamRequest
+}
+
+func newProxyTestHarness(t *testing.T, applications []*appconnv1alpha1.Application, ops ...Option) *proxyTestHarness {
+	core, logs := observer.New(zap.InfoLevel)
+	log, err := logger.New(logger.TEXT, logger.ERROR, core)
+	require.NoError(t, err)
+
+	h := &proxyTestHarness{
+		t:              t,
+		appCache:       cache.New(time.Minute, time.Minute),
+		logs:           logs,
+		upstreamStatus: http.StatusOK,
+		upstreamHeader: http.Header{},
+	}
+
+	h.upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		h.mu.Lock()
+		h.upstreamRequests = append(h.upstreamRequests, upstreamRequest{Method: r.Method, Host: r.Host, Path: r.URL.Path, Header: r.Header.Clone(), Body: string(body)})
+		if handler := h.upstreamHandler; handler != nil {
+			// the handler runs unlocked, so that it can block without blocking the other requests
+			h.mu.Unlock()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			handler.ServeHTTP(w, r)
+			return
+		}
+		defer h.mu.Unlock()
+		for key, values := range h.upstreamHeader {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(h.upstreamStatus)
+	}))
+	t.Cleanup(h.upstream.Close)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, appconnv1alpha1.AddToScheme(scheme))
+
+	clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, application := range applications {
+		clientBuilder = clientBuilder.WithObjects(application.DeepCopy())
+	}
+
+	controller.NewCacheSync(log, clientBuilder.Build(), h.appCache, "test-controller",
+		harnessAppNamePlaceholder, harnessPathPrefixV1, harnessPathPrefixV2, harnessPathPrefixEvents).Init(context.Background())
+
+	h.handler, err = NewProxyHandlerFromConfig(ProxyConfig{
+		EventingPublisherHost:   strings.TrimPrefix(h.upstream.URL, "http://"),
+		EventingDestinationPath: eventingDestinationPathPublish,
+	}, h.appCache, log, ops...)
+	require.NoError(t, err)
+
+	h.server = httptest.NewServer(NewHandler(http.HandlerFunc(h.handler.ProxyAppConnectorRequests), harnessHealthPath))
+	t.Cleanup(h.server.Close)
+
+	return h
+}
+
+// setUpstreamStatus sets the status code the upstream answers with
+func (h *proxyTestHarness) setUpstreamStatus(status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.upstreamStatus = status
+}
+
+// setUpstreamHeader sets the header the upstream answers with
+func (h *proxyTestHarness) setUpstreamHeader(key, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.upstreamHeader.Set(key, value)
+}
+
+// setUpstreamHandler replaces the answer of the upstream with the handler, the requests are still recorded.
+// The nil handler restores the status and the header set with setUpstreamStatus and setUpstreamHeader.
+func (h *proxyTestHarness) setUpstreamHandler(handler http.Handler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.upstreamHandler = handler
+}
+
+// receivedRequests returns the requests received by the upstream so far
+func (h *proxyTestHarness) receivedRequests() []upstreamRequest {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]upstreamRequest(nil), h.upstreamRequests...)
+}
+
+// do sends the request to the proxy, an empty certInfoHeader omits the certificate header
+func (h *proxyTestHarness) do(method, path, certInfoHeader string) *http.Response {
+	return h.doWithBody(method, path, certInfoHeader, "", "")
+}
+
+// doWithBody sends the request with the body to the proxy, an empty contentType omits the Content-Type header
+func (h *proxyTestHarness) doWithBody(method, path, certInfoHeader, contentType, body string) *http.Response {
+	req := h.newRequest(method, path, certInfoHeader, body)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return h.send(req)
+}
+
+// newRequest creates the request to the proxy, so that the test can adjust it before sending it.
+// An empty certInfoHeader omits the certificate header.
+func (h *proxyTestHarness) newRequest(method, path, certInfoHeader, body string) *http.Request {
+	req, err := http.NewRequest(method, h.server.URL+path, strings.NewReader(body))
+	require.NoError(h.t, err)
+	if certInfoHeader != "" {
+		req.Header.Set(CertificateInfoHeader, certInfoHeader)
+	}
+
+	return req
+}
+
+// send sends the request to the proxy
+func (h *proxyTestHarness) send(req *http.Request) *http.Response {
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(h.t, err)
+	h.t.Cleanup(func() { res.Body.Close() })
+
+	return res
+}
+
+// errorResponse decodes the error body returned by the proxy
+func (h *proxyTestHarness) errorResponse(res *http.Response) httperrors.ErrorResponse {
+	body, err := io.ReadAll(res.Body)
+	require.NoError(h.t, err)
+
+	var errorResponse httperrors.ErrorResponse
+	require.NoError(h.t, json.Unmarshal(body, &errorResponse))
+
+	return errorResponse
+}
+
+// applicationWithClientIDs returns the application named applicationName, managed by Compass with the client IDs
+func applicationWithClientIDs(clientIDs ...string) *appconnv1alpha1.Application {
+	application := applicationNotManagedByCompass.DeepCopy()
+	application.Spec.CompassMetadata = &appconnv1alpha1.CompassMetadata{Authentication: appconnv1alpha1.Authentication{ClientIds: clientIDs}}
+	return application
+}
@@ -0,0 +1,155 @@
+package validationproxy
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	appconnv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_EndToEnd(t *testing.T) {
+	applications := []*appconnv1alpha1.Application{applicationManagedByCompass, applicationNotManagedByCompass}
+
+	t.Run("should proxy request of application not managed by Compass to legacy events", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, applications)
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/v1/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		requests := h.receivedRequests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, fmt.Sprintf("/%s/v1/events", applicationName), requests[0].Path)
+		assert.Empty(t, requests[0].Header.Get(CertificateInfoHeader))
+	})
+
+	t.Run("should proxy request of application managed by Compass to cloud events", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, applications)
+
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationMetaName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationID))
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		requests := h.receivedRequests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, eventingDestinationPathPublish, requests[0].Path)
+	})
+
+	t.Run("should answer health path without certificate", func(t *testing.T) {
+		// given
+		h := newProxyTestHarness(t, applications)
+
+		// when
+		res := h.do(http.MethodGet, harnessHealthPath, "")
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Empty(t, h.receivedRequests())
+	})
+
+	errorCases := []struct {
+		caseDescription   string
+		path              string
+		certInfoHeader    string
+		upstreamStatus    int
+		expectedStatus    int
+		expectedErrorCode string
+	}{
+		{
+			caseDescription:   "missing certificate header",
+			path:              fmt.Sprintf("/%s/v1/events", applicationName),
+			expectedStatus:    http.StatusInternalServerError,
+			expectedErrorCode: ErrorCodeCertificateHeaderNotFound,
+		},
+		{
+			caseDescription:   "unknown application",
+			path:              "/unknown-application/v1/events",
+			certInfoHeader:    fmt.Sprintf(harnessCertInfoHeaderValue, "unknown-application"),
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeAppNotFound,
+		},
+		{
+			caseDescription:   "subject not matching application name",
+			path:              fmt.Sprintf("/%s/v1/events", applicationName),
+			certInfoHeader:    fmt.Sprintf(harnessCertInfoHeaderValue, "other-application"),
+			expectedStatus:    http.StatusForbidden,
+			expectedErrorCode: ErrorCodeForbiddenNoSubject,
+		},
+		{
+			caseDescription:   "subject not matching Compass client IDs",
+			path:              fmt.Sprintf("/%s/v1/events", applicationMetaName),
+			certInfoHeader:    fmt.Sprintf(harnessCertInfoHeaderValue, applicationMetaName),
+			expectedStatus:    http.StatusForbidden,
+			expectedErrorCode: ErrorCodeForbiddenNoSubject,
+		},
+		{
+			caseDescription:   "path without destination",
+			path:              fmt.Sprintf("/%s/v3/events", applicationName),
+			certInfoHeader:    fmt.Sprintf(harnessCertInfoHeaderValue, applicationName),
+			expectedStatus:    http.StatusNotFound,
+			expectedErrorCode: ErrorCodeDestinationNotFound,
+		},
+		{
+			caseDescription: "upstream server error",
+			path:            fmt.Sprintf("/%s/v2/events", applicationName),
+			certInfoHeader:  fmt.Sprintf(harnessCertInfoHeaderValue, applicationName),
+			upstreamStatus:  http.StatusInternalServerError,
+			expectedStatus:  http.StatusBadGateway,
+		},
+	}
+
+	for _, testCase := range errorCases {
+		t.Run("should reject request with "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			h := newProxyTestHarness(t, applications)
+			if testCase.upstreamStatus != 0 {
+				h.setUpstreamStatus(testCase.upstreamStatus)
+			}
+
+			// when
+			res := h.do(http.MethodPost, testCase.path, testCase.certInfoHeader)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, res.StatusCode)
+			if testCase.expectedErrorCode != "" {
+				assert.Empty(t, h.receivedRequests())
+				assert.Equal(t, testCase.expectedErrorCode, h.errorResponse(res).ErrorCode)
+			}
+		})
+	}
+}
+
+func TestProxy_DisabledApplication(t *testing.T) {
+	disabledApplication := applicationNotManagedByCompass.DeepCopy()
+	disabledApplication.Name = "disabled-application"
+	disabledApplication.Annotations = map[string]string{controller.ProxyDisabledAnnotation: "true"}
+
+	h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass, disabledApplication})
+
+	t.Run("should proxy request of enabled application", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("should return 503 for disabled application", func(t *testing.T) {
+		// when
+		res := h.do(http.MethodPost, "/disabled-application/events", fmt.Sprintf(harnessCertInfoHeaderValue, "disabled-application"))
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.Equal(t, ErrorCodeAppDisabled, h.errorResponse(res).ErrorCode)
+		assert.Len(t, h.receivedRequests(), 1)
+	})
+}
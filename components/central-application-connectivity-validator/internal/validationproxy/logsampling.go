@@ -0,0 +1,39 @@
+package validationproxy
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type logSampledKey struct{}
+
+// logSampler selects 1 in rate requests whose informational logs are written, the nil logSampler selects all of them
+type logSampler struct {
+	rate    uint64
+	counter atomic.Uint64
+}
+
+func newLogSampler(rate int) *logSampler {
+	if rate <= 1 {
+		return nil
+	}
+	return &logSampler{rate: uint64(rate)}
+}
+
+func (s *logSampler) sample() bool {
+	if s == nil {
+		return true
+	}
+	return (s.counter.Add(1)-1)%s.rate == 0
+}
+
+// withLogSampled stores the sampling decision in the request context, so that the proxy follows it
+func withLogSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, logSampledKey{}, sampled)
+}
+
+// isLogSampled reports whether the informational logs of the request are written, by default they are
+func isLogSampled(ctx context.Context) bool {
+	sampled, ok := ctx.Value(logSampledKey{}).(bool)
+	return !ok || sampled
+}
@@ -0,0 +1,45 @@
+package validationproxy
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	clientIDCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "central_application_connectivity_validator_client_id_cache_hits_total",
+		Help: "Number of client ID lookups answered from the cache",
+	})
+	clientIDCacheMisses = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "central_application_connectivity_validator_client_id_cache_misses_total",
+		Help: "Number of client ID lookups for applications missing in the cache",
+	})
+	clientIDFetchErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "central_application_connectivity_validator_client_id_fetch_errors_total",
+		Help: "Number of failed client ID lookups in the fallback client ID source",
+	})
+)
+
+// NewCachedApplicationsGauge creates the gauge reporting the number of applications in the cache, evaluated on every scrape.
+// It is not registered, as the cache is owned by the caller.
+func NewCachedApplicationsGauge(cache Cache) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "central_application_connectivity_validator_cached_applications",
+		Help: "Number of applications in the client ID cache",
+	}, func() float64 {
+		return float64(cache.ItemCount())
+	})
+}
+
+// RegisterMetrics registers the metrics of the proxy and the additional collectors. Registering continues after a failure,
+// the metrics failing to register are still updated, but not exposed.
+func RegisterMetrics(registerer prometheus.Registerer, collectors ...prometheus.Collector) error {
+	var errs []error
+	for _, collector := range append([]prometheus.Collector{clientIDCacheHits, clientIDCacheMisses, clientIDFetchErrors}, collectors...) {
+		if err := registerer.Register(collector); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
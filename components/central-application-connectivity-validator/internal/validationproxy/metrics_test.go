@@ -0,0 +1,72 @@
+package validationproxy
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	appconnv1alpha1 "github.com/kyma-project/kyma/components/central-application-gateway/pkg/apis/applicationconnector/v1alpha1"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedApplicationsGauge(t *testing.T) {
+	t.Run("should report the number of cached applications", func(t *testing.T) {
+		// given
+		idCache := cache.New(cache.NoExpiration, cache.NoExpiration)
+		gauge := NewCachedApplicationsGauge(idCache)
+
+		// when
+		idCache.Set("app-1", controller.CachedAppData{}, cache.NoExpiration)
+		idCache.Set("app-2", controller.CachedAppData{}, cache.NoExpiration)
+		idCache.Set("app-2", controller.CachedAppData{ClientIDs: []string{"client-id"}}, cache.NoExpiration)
+
+		// then
+		assert.Equal(t, float64(2), testutil.ToFloat64(gauge))
+
+		// when
+		idCache.Delete("app-1")
+
+		// then
+		assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+	})
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	t.Run("should register the metrics", func(t *testing.T) {
+		// given
+		registry := prometheus.NewRegistry()
+
+		// when
+		err := RegisterMetrics(registry, NewCachedApplicationsGauge(cache.New(cache.NoExpiration, cache.NoExpiration)))
+
+		// then
+		require.NoError(t, err)
+		count, err := testutil.GatherAndCount(registry)
+		require.NoError(t, err)
+		assert.Equal(t, 4, count)
+	})
+
+	t.Run("should register the other metrics and keep serving requests when registration fails", func(t *testing.T) {
+		// given
+		registry := prometheus.NewRegistry()
+		require.NoError(t, registry.Register(prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "central_application_connectivity_validator_client_id_cache_hits_total",
+			Help: "Conflicting metric",
+		})))
+
+		// when
+		err := RegisterMetrics(registry)
+		h := newProxyTestHarness(t, []*appconnv1alpha1.Application{applicationNotManagedByCompass})
+		res := h.do(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), fmt.Sprintf(harnessCertInfoHeaderValue, applicationName))
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, 1, testutil.CollectAndCount(registry, "central_application_connectivity_validator_client_id_cache_misses_total"))
+	})
+}
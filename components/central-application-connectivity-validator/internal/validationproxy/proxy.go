@@ -6,10 +6,18 @@ import (
 	"github.com/gorilla/mux"
 )
 
-func NewHandler(proxyHandler http.Handler) http.Handler {
+// NewHandler creates the router of the proxy. If healthPath is not empty, requests to exactly this path are answered
+// with 200 without the certificate validation, so the load balancers can check if the proxy is alive.
+func NewHandler(proxyHandler http.Handler, healthPath string) http.Handler {
 
 	router := mux.NewRouter()
 
+	if healthPath != "" {
+		router.Path(healthPath).HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	}
+
 	router.PathPrefix("/{application}/").HandlerFunc(proxyHandler.ServeHTTP)
 
 	return router
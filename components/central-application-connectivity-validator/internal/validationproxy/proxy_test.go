@@ -0,0 +1,65 @@
+package validationproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler(t *testing.T) {
+	proxiedApplication := ""
+	proxyHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedApplication = mux.Vars(r)["application"]
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	testCases := []struct {
+		caseDescription     string
+		healthPath          string
+		path                string
+		expectedStatus      int
+		expectedApplication string
+	}{
+		{
+			caseDescription: "answer health path without proxying",
+			healthPath:      "/healthz",
+			path:            "/healthz",
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			caseDescription:     "proxy requests of application named healthz",
+			healthPath:          "/healthz",
+			path:                "/healthz/v1/events",
+			expectedStatus:      http.StatusForbidden,
+			expectedApplication: "healthz",
+		},
+		{
+			caseDescription: "not answer health path when disabled",
+			path:            "/healthz",
+			expectedStatus:  http.StatusNotFound,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			proxiedApplication = ""
+			handler := NewHandler(proxyHandler, testCase.healthPath)
+
+			req, err := http.NewRequest(http.MethodGet, testCase.path, nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(recorder, req)
+
+			// then
+			assert.Equal(t, testCase.expectedStatus, recorder.Code)
+			assert.Equal(t, testCase.expectedApplication, proxiedApplication)
+		})
+	}
+}
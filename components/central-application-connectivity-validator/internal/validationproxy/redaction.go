@@ -0,0 +1,17 @@
+package validationproxy
+
+import "regexp"
+
+const redactedValue = "***"
+
+// pathRedactor replaces sensitive segments of the logged request paths
+type pathRedactor struct {
+	patterns []*regexp.Regexp
+}
+
+func (r *pathRedactor) redact(path string) string {
+	for _, pattern := range r.patterns {
+		path = pattern.ReplaceAllString(path, redactedValue)
+	}
+	return path
+}
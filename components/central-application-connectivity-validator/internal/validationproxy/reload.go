@@ -0,0 +1,137 @@
+package validationproxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/kyma-project/kyma/common/logging/logger"
+	"sigs.k8s.io/yaml"
+)
+
+// ProxyConfig identifies the destination of the proxied requests. It is the part of the proxy configuration
+// which can be reloaded from a file without restart.
+type ProxyConfig struct {
+	EventingPublisherHost   string `json:"eventingPublisherHost"`
+	EventingDestinationPath string `json:"eventingDestinationPath"`
+}
+
+// LoadProxyConfig reads ProxyConfig from the YAML file
+func LoadProxyConfig(path string) (ProxyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ProxyConfig{}, fmt.Errorf("while reading proxy config file %s: %s", path, err)
+	}
+	return parseProxyConfig(data)
+}
+
+func parseProxyConfig(data []byte) (ProxyConfig, error) {
+	var config ProxyConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return ProxyConfig{}, fmt.Errorf("while parsing proxy config: %s", err)
+	}
+	if config.EventingPublisherHost == "" {
+		return ProxyConfig{}, fmt.Errorf("proxy config should contain eventingPublisherHost")
+	}
+	if config.EventingDestinationPath == "" {
+		return ProxyConfig{}, fmt.Errorf("proxy config should contain eventingDestinationPath")
+	}
+	if err := validateProxyParameters(config.EventingPublisherHost, config.EventingDestinationPath); err != nil {
+		return ProxyConfig{}, fmt.Errorf("invalid proxy config: %s", err)
+	}
+	return config, nil
+}
+
+// ReloadingProxyHandler is ProxyHandler whose proxies can be rebuilt with a new configuration
+type ReloadingProxyHandler interface {
+	ProxyHandler
+	Reload(config ProxyConfig)
+	Config() ProxyConfig
+}
+
+type reloadedProxyHandler struct {
+	config  ProxyConfig
+	handler ProxyHandler
+}
+
+type reloadingProxyHandler struct {
+	current atomic.Pointer[reloadedProxyHandler]
+	cache   Cache
+	log     *logger.Logger
+	ops     []Option
+}
+
+// NewReloadingProxyHandler creates ReloadingProxyHandler. On every reload the proxy handler is rebuilt with the options
+// and swapped atomically, so requests already being proxied complete with the previous proxies. It returns an error
+// when the initial config is malformed, the malformed configs of the reloads are logged and skipped.
+func NewReloadingProxyHandler(config ProxyConfig, cache Cache, log *logger.Logger, ops ...Option) (ReloadingProxyHandler, error) {
+	h := &reloadingProxyHandler{
+		cache: cache,
+		log:   log,
+		ops:   ops,
+	}
+	proxyHandler, err := NewProxyHandlerFromConfig(config, cache, log, ops...)
+	if err != nil {
+		return nil, err
+	}
+	h.current.Store(&reloadedProxyHandler{config: config, handler: proxyHandler})
+	return h, nil
+}
+
+func (h *reloadingProxyHandler) Reload(config ProxyConfig) {
+	proxyHandler, err := NewProxyHandlerFromConfig(config, h.cache, h.log, h.ops...)
+	if err != nil {
+		h.log.WithContext().Errorf("Proxy handler not reloaded: %s", err.Error())
+		return
+	}
+	h.current.Store(&reloadedProxyHandler{config: config, handler: proxyHandler})
+}
+
+func (h *reloadingProxyHandler) Config() ProxyConfig {
+	return h.current.Load().config
+}
+
+func (h *reloadingProxyHandler) ProxyAppConnectorRequests(w http.ResponseWriter, r *http.Request) {
+	h.current.Load().handler.ProxyAppConnectorRequests(w, r)
+}
+
+// WatchProxyConfig reloads the handler whenever the configuration in the file differs from the one in use, until
+// the context is done. Invalid configurations are logged and skipped, so the last valid configuration stays in use.
+func WatchProxyConfig(ctx context.Context, path string, interval time.Duration, handler ReloadingProxyHandler, log *logger.Logger) {
+	var lastInvalidData []byte
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.WithContext().Warnf("Unable to read proxy config file %s: %s", path, err.Error())
+			continue
+		}
+		config, err := parseProxyConfig(data)
+		if err != nil {
+			if !bytes.Equal(data, lastInvalidData) {
+				log.WithContext().Errorf("Proxy config file %s not reloaded: %s", path, err.Error())
+				lastInvalidData = data
+			}
+			continue
+		}
+		if config == handler.Config() {
+			continue
+		}
+
+		handler.Reload(config)
+		log.WithContext().Infof("Proxy config reloaded from %s with eventing publisher host %s", path, config.EventingPublisherHost)
+	}
+}
@@ -0,0 +1,196 @@
+package validationproxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/kyma-project/kyma/common/logging/logger"
+	"github.com/kyma-project/kyma/components/central-application-connectivity-validator/internal/controller"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProxyConfig(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		content         string
+		expectedConfig  ProxyConfig
+		valid           bool
+	}{
+		{
+			caseDescription: "complete config",
+			content:         "eventingPublisherHost: eventing-publisher:8080\neventingDestinationPath: /publish\n",
+			expectedConfig:  ProxyConfig{EventingPublisherHost: "eventing-publisher:8080", EventingDestinationPath: "/publish"},
+			valid:           true,
+		},
+		{
+			caseDescription: "config without eventing publisher host",
+			content:         "eventingDestinationPath: /publish\n",
+			valid:           false,
+		},
+		{
+			caseDescription: "config with malformed eventing publisher host",
+			content:         "eventingPublisherHost: http://eventing-publisher:8080\neventingDestinationPath: /publish\n",
+			valid:           false,
+		},
+		{
+			caseDescription: "config with unknown field",
+			content:         "eventingPublisherHost: eventing-publisher:8080\neventingDestinationPath: /publish\nunknown: value\n",
+			valid:           false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should load "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(testCase.content), 0600))
+
+			// when
+			config, err := LoadProxyConfig(path)
+
+			// then
+			assert.Equal(t, testCase.valid, err == nil)
+			assert.Equal(t, testCase.expectedConfig, config)
+		})
+	}
+}
+
+func TestWatchProxyConfig(t *testing.T) {
+	log, err := logger.New(logger.TEXT, logger.ERROR)
+	require.NoError(t, err)
+
+	blocked, release := make(chan struct{}, 1), make(chan struct{})
+	newUpstream := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Block") != "" {
+				blocked <- struct{}{}
+				<-release
+			}
+			w.Header().Set("X-Upstream", name)
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+	firstUpstream, secondUpstream := newUpstream("first"), newUpstream("second")
+	releaseOnce := sync.OnceFunc(func() { close(release) })
+	t.Cleanup(releaseOnce)
+
+	writeConfig := func(path string, upstream *httptest.Server) {
+		content := fmt.Sprintf("eventingPublisherHost: %s\neventingDestinationPath: /publish\n", strings.TrimPrefix(upstream.URL, "http://"))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	idCache := cache.New(time.Minute, time.Minute)
+	idCache.Set(applicationName, controller.CachedAppData{
+		ClientIDs:           []string{},
+		AppPathPrefixV1:     fmt.Sprintf("/%s/v1/events", applicationName),
+		AppPathPrefixV2:     fmt.Sprintf("/%s/v2/events", applicationName),
+		AppPathPrefixEvents: fmt.Sprintf("/%s/events", applicationName),
+	}, cache.NoExpiration)
+
+	serve := func(handler ProxyHandler, block bool) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/%s/events", applicationName), nil)
+		req.Header.Set(CertificateInfoHeader, `Hash=1;Subject="CN=test-application,OU=OrgUnit,O=Organization,L=Waldorf,ST=Waldorf,C=DE";URI=`)
+		if block {
+			req.Header.Set("X-Block", "true")
+		}
+		req = mux.SetURLVars(req, map[string]string{"application": applicationName})
+
+		recorder := httptest.NewRecorder()
+		handler.ProxyAppConnectorRequests(recorder, req)
+		return recorder
+	}
+
+	t.Run("should swap the eventing publisher host on reload without dropping in-flight requests", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfig(path, firstUpstream)
+
+		config, err := LoadProxyConfig(path)
+		require.NoError(t, err)
+		handler, err := NewReloadingProxyHandler(config, idCache, log)
+		require.NoError(t, err)
+		require.Equal(t, "first", serve(handler, false).Header().Get("X-Upstream"))
+
+		inFlight := make(chan *httptest.ResponseRecorder, 1)
+		go func() { inFlight <- serve(handler, true) }()
+		<-blocked
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go WatchProxyConfig(ctx, path, 10*time.Millisecond, handler, log)
+
+		// when
+		writeConfig(path, secondUpstream)
+
+		// then
+		require.Eventually(t, func() bool {
+			return serve(handler, false).Header().Get("X-Upstream") == "second"
+		}, 2*time.Second, 10*time.Millisecond)
+
+		releaseOnce()
+		inFlightRecorder := <-inFlight
+		assert.Equal(t, http.StatusOK, inFlightRecorder.Code)
+		assert.Equal(t, "first", inFlightRecorder.Header().Get("X-Upstream"))
+	})
+
+	t.Run("should keep the last valid config when the file becomes invalid", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfig(path, firstUpstream)
+
+		config, err := LoadProxyConfig(path)
+		require.NoError(t, err)
+		handler, err := NewReloadingProxyHandler(config, idCache, log)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go WatchProxyConfig(ctx, path, 10*time.Millisecond, handler, log)
+
+		// when
+		require.NoError(t, os.WriteFile(path, []byte("eventingPublisherHost: \"\"\n"), 0600))
+		time.Sleep(50 * time.Millisecond)
+
+		// then
+		assert.Equal(t, "first", serve(handler, false).Header().Get("X-Upstream"))
+	})
+	t.Run("should keep the current handler on reload with malformed config", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfig(path, firstUpstream)
+
+		config, err := LoadProxyConfig(path)
+		require.NoError(t, err)
+		handler, err := NewReloadingProxyHandler(config, idCache, log)
+		require.NoError(t, err)
+
+		// when
+		handler.Reload(ProxyConfig{EventingPublisherHost: config.EventingDestinationPath, EventingDestinationPath: config.EventingPublisherHost})
+
+		// then
+		assert.Equal(t, config, handler.Config())
+		assert.Equal(t, "first", serve(handler, false).Header().Get("X-Upstream"))
+	})
+
+	t.Run("should reject malformed initial config", func(t *testing.T) {
+		// when
+		handler, err := NewReloadingProxyHandler(ProxyConfig{EventingPublisherHost: "", EventingDestinationPath: "/publish"}, idCache, log)
+
+		// then
+		require.Error(t, err)
+		assert.Nil(t, handler)
+	})
+}
@@ -0,0 +1,41 @@
+package validationproxy
+
+import (
+	"net/http/httputil"
+	"strings"
+
+	"github.com/kyma-project/kyma/common/logging/logger"
+)
+
+// EventsAPIRoute routes the requests with the path prefix to an additional destination, next to the legacy events
+// and the cloud events destinations
+type EventsAPIRoute struct {
+	Destination Destination
+	// PathPrefix contains AppNamePlaceholder, which is replaced with the application name, for example /%%APP_NAME%%/v3/events
+	PathPrefix         string
+	AppNamePlaceholder string
+	// DestinationHost and DestinationPath identify the endpoint receiving the requests, an empty DestinationPath keeps the request path
+	DestinationHost string
+	DestinationPath string
+}
+
+type eventsAPIRoute struct {
+	EventsAPIRoute
+	proxy *httputil.ReverseProxy
+}
+
+func (r *eventsAPIRoute) matches(path, applicationName string) bool {
+	return strings.HasPrefix(path, strings.ReplaceAll(r.PathPrefix, r.AppNamePlaceholder, applicationName))
+}
+
+func newEventsAPIRoute(route EventsAPIRoute, log *logger.Logger, redactor *pathRedactor) *eventsAPIRoute {
+	reqOpts := []requestOption{withEmptyRequestHost, withEmptyXFwdClientCert, withHTTPScheme}
+	if route.DestinationPath != "" {
+		reqOpts = append([]requestOption{withRewriteBaseURL(route.DestinationPath)}, reqOpts...)
+	}
+
+	return &eventsAPIRoute{
+		EventsAPIRoute: route,
+		proxy:          createReverseProxy(log, redactor, route.DestinationHost, reqOpts...),
+	}
+}
@@ -0,0 +1,78 @@
+package validationproxy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SANValidationMode defines how the URI Subject Alternative Names presented in the certificate header are evaluated
+type SANValidationMode string
+
+const (
+	// SANValidationModeDisabled ignores the Subject Alternative Names, only the certificate subjects are validated
+	SANValidationModeDisabled SANValidationMode = "disabled"
+	// SANValidationModeRequired accepts the request only if both the subject and a URI Subject Alternative Name are valid
+	SANValidationModeRequired SANValidationMode = "required"
+	// SANValidationModeAlternative accepts the request if either the subject or a URI Subject Alternative Name is valid
+	SANValidationModeAlternative SANValidationMode = "alternative"
+)
+
+// sanURIRegex matches the URI elements of the certificate header, the value is quoted when it contains special characters.
+// The other quoted values are matched too, so that the URI-like content of the subject is skipped.
+var sanURIRegex = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|(?:^|[;,])URI=("(?:[^"\\]|\\.)*"|[^;,]*)`)
+
+func extractSANURIs(certInfoData string) []string {
+	var uris []string
+	for _, match := range sanURIRegex.FindAllStringSubmatch(certInfoData, -1) {
+		uri := strings.TrimSpace(get(match, 1))
+		if strings.HasPrefix(uri, `"`) {
+			uri = strings.ReplaceAll(strings.Trim(uri, `"`), `\"`, `"`)
+		}
+		if uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// validateSANURIs returns nil if one of the URIs is the prefix followed by the application name
+func validateSANURIs(uris []string, uriPrefix, applicationName string) error {
+	expected := uriPrefix + applicationName
+	for _, uri := range uris {
+		if uri == expected {
+			return nil
+		}
+	}
+	return &SubjectMismatchError{Field: "URI", Expected: fmt.Sprintf("'%s'", expected), Actual: strings.Join(uris, ", ")}
+}
+
+// identityValidationStrategy names the strategy which approved the identity in the SAN validation mode
+func identityValidationStrategy(mode SANValidationMode, subjectStrategy string, subjectErr error) string {
+	switch mode {
+	case SANValidationModeRequired:
+		return subjectStrategy + "+" + ValidationStrategySANURI
+	case SANValidationModeAlternative:
+		if subjectErr != nil {
+			return ValidationStrategySANURI
+		}
+	}
+	return subjectStrategy
+}
+
+// combineIdentityValidation applies the SAN validation mode to the results of the subject and SAN validation
+func combineIdentityValidation(mode SANValidationMode, subjectErr, sanErr error) error {
+	switch mode {
+	case SANValidationModeRequired:
+		if subjectErr != nil {
+			return subjectErr
+		}
+		return sanErr
+	case SANValidationModeAlternative:
+		if subjectErr == nil || sanErr == nil {
+			return nil
+		}
+		return subjectErr
+	}
+	return subjectErr
+}
@@ -0,0 +1,47 @@
+package validationproxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractSANURIs(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		certInfoHeader  string
+		expectedURIs    []string
+	}{
+		{
+			caseDescription: "empty URI",
+			certInfoHeader:  `Hash=1;Subject="CN=test-application";URI=`,
+			expectedURIs:    nil,
+		},
+		{
+			caseDescription: "multiple URIs of one certificate",
+			certInfoHeader:  `Hash=1;Subject="CN=test-application";URI=spiffe://cluster.local/a;URI=spiffe://cluster.local/b;DNS=test.local`,
+			expectedURIs:    []string{"spiffe://cluster.local/a", "spiffe://cluster.local/b"},
+		},
+		{
+			caseDescription: "URIs of multiple certificates",
+			certInfoHeader:  `Hash=1;URI=spiffe://cluster.local/a,Hash=2;Subject="CN=test-application,O=Organization";URI=spiffe://cluster.local/b`,
+			expectedURIs:    []string{"spiffe://cluster.local/a", "spiffe://cluster.local/b"},
+		},
+		{
+			caseDescription: "quoted URI",
+			certInfoHeader:  `Hash=1;URI="urn:app;name=test-application"`,
+			expectedURIs:    []string{"urn:app;name=test-application"},
+		},
+		{
+			caseDescription: "URI-like content of the subject",
+			certInfoHeader:  `Hash=1;Subject="CN=test-application,URI=spiffe://cluster.local/a";URI=`,
+			expectedURIs:    nil,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should extract "+testCase.caseDescription, func(t *testing.T) {
+			assert.Equal(t, testCase.expectedURIs, extractSANURIs(testCase.certInfoHeader))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package validationproxy
+
+import (
+	"crypto/x509/pkix"
+	"fmt"
+	"strings"
+)
+
+// SubjectValidator decides whether the certificate subject is allowed to send requests on behalf of the application.
+// It returns nil for the allowed subject, otherwise the reason of the rejection, preferably SubjectMismatchError.
+type SubjectValidator interface {
+	Validate(subject pkix.Name, applicationName string, applicationClientIDs []string) error
+}
+
+// Names of the strategies approving the identity of the request, reported in the logs
+const (
+	ValidationStrategyApplicationName = "application-name"
+	ValidationStrategyClientID        = "client-id"
+	ValidationStrategySANURI          = "san-uri"
+	ValidationStrategyCustom          = "custom"
+)
+
+// SubjectMismatchError describes the certificate subject field not matching the application.
+// Expected describes the expected value and must not contain the application client IDs.
+// The mismatches of CN, O, and OU are reported with the subject attributes read into these fields.
+type SubjectMismatchError struct {
+	Field    string
+	Expected string
+	Actual   string
+}
+
+func (e *SubjectMismatchError) Error() string {
+	return fmt.Sprintf("subject field %s does not match: expected %s, got '%s'", e.Field, e.Expected, e.Actual)
+}
+
+// Summary returns the description of the mismatch without the expected and actual values, safe to return to the client
+func (e *SubjectMismatchError) Summary() string {
+	return fmt.Sprintf("subject field %s does not match the application", e.Field)
+}
+
+// ClientIDNormalization defines how the Common Name, the application client IDs and the application name
+// are normalized before they are compared
+type ClientIDNormalization struct {
+	TrimSpace  bool
+	IgnoreCase bool
+}
+
+func (n ClientIDNormalization) equal(a, b string) bool {
+	if n.TrimSpace {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	}
+	if n.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+// SubjectOrganization is the organization and the organizational unit which the certificate subjects must present,
+// the empty fields are not validated
+type SubjectOrganization struct {
+	Organization       string
+	OrganizationalUnit string
+}
+
+type defaultSubjectValidator struct {
+	normalization ClientIDNormalization
+	organization  SubjectOrganization
+}
+
+// NewDefaultSubjectValidator creates SubjectValidator which matches the Common Name with the application client IDs,
+// or with the application name when the application has no client IDs
+func NewDefaultSubjectValidator() SubjectValidator {
+	return defaultSubjectValidator{}
+}
+
+// NewNormalizingSubjectValidator creates SubjectValidator like NewDefaultSubjectValidator, which compares the values
+// after the normalization
+func NewNormalizingSubjectValidator(normalization ClientIDNormalization) SubjectValidator {
+	return defaultSubjectValidator{normalization: normalization}
+}
+
+// NewOrganizationSubjectValidator creates SubjectValidator like NewNormalizingSubjectValidator, which also requires
+// the subject to present the organization and the organizational unit. They are compared without the normalization.
+func NewOrganizationSubjectValidator(normalization ClientIDNormalization, organization SubjectOrganization) SubjectValidator {
+	return defaultSubjectValidator{normalization: normalization, organization: organization}
+}
+
+func (defaultSubjectValidator) strategy(applicationClientIDs []string) string {
+	if len(applicationClientIDs) == 0 {
+		return ValidationStrategyApplicationName
+	}
+	return ValidationStrategyClientID
+}
+
+// subjectValidationStrategy names the strategy of the subject validator, the validators replacing the default one are custom
+func subjectValidationStrategy(subjectValidator SubjectValidator, applicationClientIDs []string) string {
+	if v, ok := subjectValidator.(interface{ strategy([]string) string }); ok {
+		return v.strategy(applicationClientIDs)
+	}
+	return ValidationStrategyCustom
+}
+
+func (v defaultSubjectValidator) Validate(subject pkix.Name, applicationName string, applicationClientIDs []string) error {
+	if err := v.validateCommonName(subject, applicationName, applicationClientIDs); err != nil {
+		return err
+	}
+	if err := validateSubjectField("O", "organization", v.organization.Organization, subject.Organization); err != nil {
+		return err
+	}
+	return validateSubjectField("OU", "organizational unit", v.organization.OrganizationalUnit, subject.OrganizationalUnit)
+}
+
+func (v defaultSubjectValidator) validateCommonName(subject pkix.Name, applicationName string, applicationClientIDs []string) error {
+	if len(applicationClientIDs) == 0 {
+		if v.normalization.equal(applicationName, subject.CommonName) {
+			return nil
+		}
+		return &SubjectMismatchError{Field: "CN", Expected: fmt.Sprintf("application name '%s'", applicationName), Actual: subject.CommonName}
+	}
+
+	for _, id := range applicationClientIDs {
+		if v.normalization.equal(subject.CommonName, id) {
+			return nil
+		}
+	}
+	return &SubjectMismatchError{Field: "CN", Expected: fmt.Sprintf("one of %d application client IDs", len(applicationClientIDs)), Actual: subject.CommonName}
+}
+
+// validateSubjectField checks that one of the values of the subject field is the expected one, nothing is expected
+// when the expected value is empty
+func validateSubjectField(field, description, expected string, values []string) error {
+	if expected == "" {
+		return nil
+	}
+	for _, value := range values {
+		if value == expected {
+			return nil
+		}
+	}
+	return &SubjectMismatchError{Field: field, Expected: fmt.Sprintf("%s '%s'", description, expected), Actual: strings.Join(values, "+")}
+}
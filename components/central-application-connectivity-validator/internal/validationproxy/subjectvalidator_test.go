@@ -0,0 +1,220 @@
+package validationproxy
+
+import (
+	"crypto/x509/pkix"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultSubjectValidator(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		commonName      string
+		clientIDs       []string
+		valid           bool
+	}{
+		{
+			caseDescription: "Common Name equal to application name without client IDs",
+			commonName:      applicationName,
+			valid:           true,
+		},
+		{
+			caseDescription: "Common Name different from application name without client IDs",
+			commonName:      "invalid-cn",
+			valid:           false,
+		},
+		{
+			caseDescription: "Common Name equal to one of client IDs",
+			commonName:      "client-2",
+			clientIDs:       []string{"client-1", "client-2"},
+			valid:           true,
+		},
+		{
+			caseDescription: "Common Name equal to application name with client IDs",
+			commonName:      applicationName,
+			clientIDs:       []string{"client-1"},
+			valid:           false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should validate "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			validator := NewDefaultSubjectValidator()
+
+			// when
+			err := validator.Validate(pkix.Name{CommonName: testCase.commonName}, applicationName, testCase.clientIDs)
+
+			// then
+			assert.Equal(t, testCase.valid, err == nil)
+		})
+	}
+}
+
+func TestNormalizingSubjectValidator(t *testing.T) {
+	testCases := []struct {
+		caseDescription string
+		normalization   ClientIDNormalization
+		commonName      string
+		clientIDs       []string
+		valid           bool
+	}{
+		{
+			caseDescription: "accept whitespace-padded client ID when trimming",
+			normalization:   ClientIDNormalization{TrimSpace: true},
+			commonName:      "client-2",
+			clientIDs:       []string{"client-1", " client-2\t"},
+			valid:           true,
+		},
+		{
+			caseDescription: "accept whitespace-padded Common Name when trimming",
+			normalization:   ClientIDNormalization{TrimSpace: true},
+			commonName:      " client-1 ",
+			clientIDs:       []string{"client-1"},
+			valid:           true,
+		},
+		{
+			caseDescription: "reject client ID of different case when only trimming",
+			normalization:   ClientIDNormalization{TrimSpace: true},
+			commonName:      "Client-1",
+			clientIDs:       []string{"client-1"},
+			valid:           false,
+		},
+		{
+			caseDescription: "accept client ID of different case when ignoring case",
+			normalization:   ClientIDNormalization{IgnoreCase: true},
+			commonName:      "Client-1",
+			clientIDs:       []string{"client-1"},
+			valid:           true,
+		},
+		{
+			caseDescription: "accept padded client ID of different case with both normalizations",
+			normalization:   ClientIDNormalization{TrimSpace: true, IgnoreCase: true},
+			commonName:      "CLIENT-1",
+			clientIDs:       []string{" client-1 "},
+			valid:           true,
+		},
+		{
+			caseDescription: "reject whitespace-padded client ID without normalization",
+			commonName:      "client-1",
+			clientIDs:       []string{" client-1 "},
+			valid:           false,
+		},
+		{
+			caseDescription: "accept application name of different case without client IDs when ignoring case",
+			normalization:   ClientIDNormalization{IgnoreCase: true},
+			commonName:      strings.ToUpper(applicationName),
+			valid:           true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			validator := NewNormalizingSubjectValidator(testCase.normalization)
+
+			// when
+			err := validator.Validate(pkix.Name{CommonName: testCase.commonName}, applicationName, testCase.clientIDs)
+
+			// then
+			assert.Equal(t, testCase.valid, err == nil)
+		})
+	}
+}
+
+func TestDefaultSubjectValidator_Reason(t *testing.T) {
+	t.Run("should identify Common Name not matching application name", func(t *testing.T) {
+		// when
+		err := NewDefaultSubjectValidator().Validate(pkix.Name{CommonName: "invalid-cn"}, applicationName, nil)
+
+		// then
+		var mismatchErr *SubjectMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, "CN", mismatchErr.Field)
+		assert.Equal(t, "invalid-cn", mismatchErr.Actual)
+		assert.Contains(t, mismatchErr.Expected, applicationName)
+	})
+
+	t.Run("should identify Common Name not matching client IDs without disclosing them", func(t *testing.T) {
+		// when
+		err := NewDefaultSubjectValidator().Validate(pkix.Name{CommonName: "invalid-cn"}, applicationName, []string{"client-1", "client-2"})
+
+		// then
+		var mismatchErr *SubjectMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, "CN", mismatchErr.Field)
+		assert.Equal(t, "invalid-cn", mismatchErr.Actual)
+		assert.NotContains(t, mismatchErr.Error(), "client-1")
+		assert.NotContains(t, mismatchErr.Error(), "client-2")
+		assert.Equal(t, "subject field CN does not match the application", mismatchErr.Summary())
+	})
+}
+
+func TestOrganizationSubjectValidator(t *testing.T) {
+	organization := SubjectOrganization{Organization: "Tenant", OrganizationalUnit: "Group"}
+
+	testCases := []struct {
+		caseDescription string
+		subject         pkix.Name
+		expectedField   string
+	}{
+		{
+			caseDescription: "accept subject with expected tenant and group",
+			subject:         pkix.Name{CommonName: applicationName, Organization: []string{"Tenant"}, OrganizationalUnit: []string{"Group"}},
+		},
+		{
+			caseDescription: "accept subject with expected tenant and group among multiple values",
+			subject:         pkix.Name{CommonName: applicationName, Organization: []string{"Other", "Tenant"}, OrganizationalUnit: []string{"Group", "Other"}},
+		},
+		{
+			caseDescription: "reject subject with mismatched tenant",
+			subject:         pkix.Name{CommonName: applicationName, Organization: []string{"Other"}, OrganizationalUnit: []string{"Group"}},
+			expectedField:   "O",
+		},
+		{
+			caseDescription: "reject subject without tenant",
+			subject:         pkix.Name{CommonName: applicationName, OrganizationalUnit: []string{"Group"}},
+			expectedField:   "O",
+		},
+		{
+			caseDescription: "reject subject with mismatched group",
+			subject:         pkix.Name{CommonName: applicationName, Organization: []string{"Tenant"}, OrganizationalUnit: []string{"Other"}},
+			expectedField:   "OU",
+		},
+		{
+			caseDescription: "reject subject with expected tenant and group but mismatched Common Name",
+			subject:         pkix.Name{CommonName: "invalid-cn", Organization: []string{"Tenant"}, OrganizationalUnit: []string{"Group"}},
+			expectedField:   "CN",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run("should "+testCase.caseDescription, func(t *testing.T) {
+			// given
+			validator := NewOrganizationSubjectValidator(ClientIDNormalization{}, organization)
+
+			// when
+			err := validator.Validate(testCase.subject, applicationName, nil)
+
+			// then
+			if testCase.expectedField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var mismatchErr *SubjectMismatchError
+			require.ErrorAs(t, err, &mismatchErr)
+			assert.Equal(t, testCase.expectedField, mismatchErr.Field)
+		})
+	}
+
+	t.Run("should not validate empty organization and organizational unit", func(t *testing.T) {
+		// when
+		err := NewOrganizationSubjectValidator(ClientIDNormalization{}, SubjectOrganization{}).Validate(pkix.Name{CommonName: applicationName}, applicationName, nil)
+
+		// then
+		assert.NoError(t, err)
+	})
+}